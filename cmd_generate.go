@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slimesolver/generator"
+	"strings"
+	"time"
+)
+
+// runGenerate writes random levels that were checked by the solver.
+func runGenerate(args []string) {
+	fs := flag.NewFlagSet("generate", flag.ExitOnError)
+	var opts generator.Options
+	fs.IntVar(&opts.Width, "width", 6, "width of the levels")
+	fs.IntVar(&opts.Height, "height", 4, "height of the levels")
+	fs.Int64Var(&opts.Seed, "seed", 1, "seed, the same seed and flags generate the same levels")
+	fs.IntVar(&opts.Count, "count", 1, "number of levels")
+	fs.IntVar(&opts.Attempts, "attempts", generator.DefaultAttempts, "random levels to try")
+	fs.Float64Var(&opts.Walls, "walls", generator.DefaultWalls, "share of tiles that are walls")
+	fs.IntVar(&opts.Slimes, "slimes", 1, "number of slimes")
+	fs.IntVar(&opts.Goals, "goals", 0, "number of goals, one per slime when 0")
+	fs.IntVar(&opts.Pits, "pits", 0, "number of pits")
+	fs.IntVar(&opts.Boxes, "boxes", 0, "number of boxes")
+	fs.IntVar(&opts.Switches, "switches", 0, "number of switches")
+	fs.IntVar(&opts.Doors, "doors", 0, "number of doors")
+	fs.IntVar(&opts.Spikes, "spikes", 0, "number of spikes")
+	fs.IntVar(&opts.MinLength, "min-length", 0, "shortest solution has at least this many moves")
+	fs.IntVar(&opts.MaxLength, "max-length", 0, "shortest solution has at most this many moves")
+	fs.Float64Var(&opts.MinScore, "min-score", 0, "lowest difficulty score, see the stats command")
+	fs.Float64Var(&opts.MaxScore, "max-score", 0, "highest difficulty score")
+	fs.IntVar(&opts.Steps, "steps", generator.DefaultSteps, "moves played backwards from the solved level with -reverse")
+	fs.IntVar(&opts.MaxStates, "max-states", generator.DefaultMaxStates, "states each check may explore")
+	require := fs.String("require", "", "comma separated mechanics every solution has to use: split, combine, push or switch")
+	reverse := fs.Bool("reverse", false, "play backwards from a solved level instead of solving random ones")
+	out := fs.String("o", "", "directory to write level files to, stdout when empty")
+	timeout := fs.Duration("timeout", 10*time.Minute, "give up after this long")
+	fs.Parse(args)
+
+	if *require != "" {
+		for _, name := range strings.Split(*require, ",") {
+			m, err := generator.ParseMechanic(strings.TrimSpace(name))
+			if err != nil {
+				log.Fatal(err)
+			}
+			opts.Require = append(opts.Require, m)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	generate := generator.Generate
+	if *reverse {
+		generate = generator.Reverse
+	}
+	levels, err := generate(ctx, opts)
+	if err != nil && !errors.Is(err, generator.ErrAttempts) {
+		log.Fatal(err)
+	}
+	if err != nil {
+		log.Printf("only %d of %d levels passed: %v", len(levels), opts.Count, err)
+	}
+
+	for i, level := range levels {
+		if *out == "" {
+			fmt.Printf("%s\n\n", level.Data)
+			continue
+		}
+		name := filepath.Join(*out, fmt.Sprintf("%02d-%d.txt", i+1, level.Seed))
+		if err := os.WriteFile(name, []byte(level.Data+"\n"), 0644); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("%s: %d moves, score %.2f, solution %s", name, level.Stats.Length, level.Stats.Score, level.Stats.Solution)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"slimesolver/levels"
+	"slimesolver/lint"
+	"time"
+)
+
+// runLint checks levels and prints their problems, it exits with 1 if any level has an error.
+func runLint(args []string) {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	pack := fs.String("pack", "", "built-in pack to check instead of level files")
+	maxNodes := fs.Int("max-nodes", lint.DefaultMaxNodes, "nodes the solver may expand per level")
+	timeout := fs.Duration("timeout", 30*time.Second, "time the solver may take per level")
+	asJSON := fs.Bool("json", false, "print the problems as JSON")
+	fs.Parse(args)
+
+	var l []levels.Level
+	if *pack != "" {
+		for _, p := range levels.Packs() {
+			if p.Name == *pack {
+				l = p.Levels
+			}
+		}
+		if len(l) == 0 {
+			log.Fatalf("pack %s not found", *pack)
+		}
+	} else {
+		paths := fs.Args()
+		if len(paths) == 0 {
+			paths = []string{defaultLevel}
+		}
+		for _, path := range paths {
+			l = append(l, levels.Level{Name: path, Data: loadLevel(path)})
+		}
+	}
+
+	type result struct {
+		Level    string         `json:"level"`
+		Problems []lint.Problem `json:"problems"`
+	}
+	results := make([]result, 0, len(l))
+	failed := false
+	for _, level := range l {
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		problems := lint.Lint(ctx, level.Data, lint.Options{MaxNodes: *maxNodes})
+		cancel()
+		failed = failed || lint.HasErrors(problems)
+		results = append(results, result{level.Name, problems})
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		for _, r := range results {
+			for _, p := range r.Problems {
+				fmt.Printf("%s:%s\n", r.Level, p)
+			}
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
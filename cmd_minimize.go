@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"slimesolver/game"
+	"slimesolver/solver"
+	"time"
+)
+
+// runMinimize shortens a replay that solves a level.
+func runMinimize(args []string) {
+	fs := flag.NewFlagSet("minimize", flag.ExitOnError)
+	moves := fs.String("moves", "", "replay that solves the level, written as u, d, l and r letters")
+	timeout := fs.Duration("timeout", time.Minute, "stop searching for shortcuts after this long")
+	maxNodes := fs.Int("max-nodes", 0, "states all searches for shortcuts may expand together, 0 means no limit")
+	fs.Parse(args)
+
+	path := defaultLevel
+	if fs.NArg() > 0 {
+		path = fs.Arg(0)
+	}
+
+	g := game.NewGame(newLogger())
+	err := g.Parse(loadLevel(path))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	replay, err := game.ParseMoves(*moves)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	result, err := solver.Minimize(ctx, g, replay, solver.Options{MaxNodes: *maxNodes, Algorithm: solver.AStar, Prune: true})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("removed %d no-ops, %d moves in loops and saved %d moves with shortcuts, expanded %d states\n", result.NoOps, result.Loops, result.Shortcuts, result.Expanded)
+	fmt.Printf("%s (%d moves, was %d)\n", game.FormatMoves(result.Moves), len(result.Moves), len(replay))
+}
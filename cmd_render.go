@@ -0,0 +1,70 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"slimesolver/game"
+	"slimesolver/render"
+	"strings"
+)
+
+// runRender draws a level to an image file, optionally after playing a replay.
+// A GIF animates the replay while PNG and SVG show the final board.
+func runRender(args []string) {
+	fs := flag.NewFlagSet("render", flag.ExitOnError)
+	out := fs.String("o", "level.png", "output file, the format is picked from the extension (.png, .svg or .gif)")
+	tileSize := fs.Int("tile", render.DefaultTileSize, "tile size in pixels")
+	moves := fs.String("moves", "", "replay to apply, written as u, d, l and r letters")
+	tween := fs.Int("tween", 0, "in-between frames drawn while actors move (gif only)")
+	delay := fs.Int("delay", render.DefaultDelay, "time each move is shown in 100ths of a second (gif only)")
+	fs.Parse(args)
+
+	path := defaultLevel
+	if fs.NArg() > 0 {
+		path = fs.Arg(0)
+	}
+
+	g := game.NewGame(newLogger())
+	err := g.Parse(loadLevel(path))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	replay, err := game.ParseMoves(*moves)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	ext := strings.ToLower(filepath.Ext(*out))
+	if ext == ".gif" {
+		err = render.GIF(f, g, replay, render.GIFOptions{
+			TileSize: *tileSize,
+			Delay:    *delay,
+			Tween:    *tween,
+		})
+	} else {
+		for _, dir := range replay {
+			g.Move(dir)
+		}
+
+		switch ext {
+		case ".png":
+			err = render.PNG(f, g, *tileSize)
+		case ".svg":
+			err = render.SVG(f, g, *tileSize)
+		default:
+			log.Fatalf("unsupported image format: %s", ext)
+		}
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"slimesolver/server"
+	"strings"
+)
+
+// runServe starts the HTTP JSON API for playing and solving levels.
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", ":8080", "address to listen on")
+	ttl := fs.Duration("ttl", server.DefaultSessionTTL, "how long an unused session is kept")
+	solveTimeout := fs.Duration("solve-timeout", server.DefaultSolveTimeout, "default time a hint or solution may take")
+	maxNodes := fs.Int("max-nodes", 0, "states a single solve may expand, 0 means no limit")
+	origins := fs.String("origins", "", "comma separated origins besides the server's own whose pages may open WebSockets")
+	fs.Parse(args)
+
+	var allowed []string
+	if *origins != "" {
+		allowed = strings.Split(*origins, ",")
+	}
+
+	s := server.New(server.Options{
+		SessionTTL:     *ttl,
+		SolveTimeout:   *solveTimeout,
+		MaxNodes:       *maxNodes,
+		AllowedOrigins: allowed,
+	})
+	log.Printf("listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, s))
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"runtime"
+	"slimesolver/game"
+	"slimesolver/solver"
+	"time"
+)
+
+// runSolve searches for a solution of a level and prints it with search statistics.
+func runSolve(args []string) {
+	fs := flag.NewFlagSet("solve", flag.ExitOnError)
+	algorithm := fs.String("algorithm", "astar", "search to use, bfs, astar or idastar")
+	heuristic := fs.String("heuristic", solver.Distance.Name, "estimate guiding astar and idastar, zero, manhattan, distance, pits or switches")
+	timeout := fs.Duration("timeout", time.Minute, "give up after this long")
+	maxNodes := fs.Int("max-nodes", 0, "states the search may expand, 0 means no limit")
+	workers := fs.Int("workers", runtime.NumCPU(), "goroutines expanding states for bfs and astar, 0 searches on one without batching")
+	all := fs.Int("all", 0, "list up to this many shortest solutions with a breadth first search instead")
+	prune := fs.Bool("prune", false, "skip states that can never be won and report how many each rule pruned")
+	fs.Parse(args)
+
+	path := defaultLevel
+	if fs.NArg() > 0 {
+		path = fs.Arg(0)
+	}
+
+	g := game.NewGame(newLogger())
+	err := g.Parse(loadLevel(path))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	opts := solver.Options{MaxNodes: *maxNodes, Prune: *prune, Workers: *workers}
+	opts.Algorithm, err = solver.ParseAlgorithm(*algorithm)
+	if err != nil {
+		log.Fatal(err)
+	}
+	opts.Heuristic, err = solver.HeuristicByName(*heuristic)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	if *all > 0 {
+		printSolutions(ctx, g, *all, opts)
+		return
+	}
+
+	start := time.Now()
+	result, err := solver.Solve(ctx, g, opts)
+	elapsed := time.Since(start)
+	fmt.Printf("expanded %d, visited %d, peak %d states in %s\n", result.Expanded, result.Visited, result.Peak, elapsed.Round(time.Millisecond))
+	if *prune {
+		for _, rule := range solver.Rules {
+			fmt.Printf("pruned %d by %s\n", result.Pruned[rule], rule)
+		}
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	optimal := "shortest"
+	if !result.Optimal {
+		optimal = "not proven shortest"
+	}
+	fmt.Printf("%s (%d moves, %s)\n", game.FormatMoves(result.Moves), len(result.Moves), optimal)
+}
+
+func printSolutions(ctx context.Context, g *game.Game, limit int, opts solver.Options) {
+	result, err := solver.Enumerate(ctx, g, limit, opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("%d shortest solutions of %d moves, expanded %d states\n", result.Total, result.Length, result.Expanded)
+	for _, moves := range result.Solutions {
+		fmt.Println(game.FormatMoves(moves))
+	}
+	if result.Total > len(result.Solutions) {
+		fmt.Printf("and %d more\n", result.Total-len(result.Solutions))
+	}
+}
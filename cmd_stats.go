@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"slimesolver/game"
+	"slimesolver/levels"
+	"slimesolver/stats"
+	"sort"
+	"time"
+)
+
+// runStats prints difficulty metrics of levels as JSON.
+// A built-in pack is ordered from the easiest level to the hardest.
+func runStats(args []string) {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	pack := fs.String("pack", "", "built-in pack to measure instead of level files")
+	maxStates := fs.Int("max-states", stats.DefaultMaxStates, "states to explore per level")
+	timeout := fs.Duration("timeout", time.Minute, "time each level may take")
+	fs.Parse(args)
+
+	var l []levels.Level
+	if *pack != "" {
+		for _, p := range levels.Packs() {
+			if p.Name == *pack {
+				l = p.Levels
+			}
+		}
+		if len(l) == 0 {
+			log.Fatalf("pack %s not found", *pack)
+		}
+	} else {
+		paths := fs.Args()
+		if len(paths) == 0 {
+			paths = []string{defaultLevel}
+		}
+		for _, path := range paths {
+			l = append(l, levels.Level{Name: path, Data: loadLevel(path)})
+		}
+	}
+
+	results := make([]stats.Stats, 0, len(l))
+	for _, level := range l {
+		g := game.NewGame(newLogger())
+		if err := g.Parse(level.Data); err != nil {
+			log.Fatalf("%s: %v", level.Name, err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		s, err := stats.Compute(ctx, g, stats.Options{MaxStates: *maxStates})
+		cancel()
+		if err != nil {
+			log.Fatalf("%s: %v", level.Name, err)
+		}
+		s.Level = level.Name
+		results = append(results, s)
+	}
+
+	if *pack != "" {
+		// levels that might not even be solvable go last
+		sort.SliceStable(results, func(i, j int) bool {
+			if results[i].Unknown != results[j].Unknown {
+				return results[j].Unknown
+			}
+			return results[i].Score < results[j].Score
+		})
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(results); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"slimesolver/game"
+)
+
+// runTrace plays a replay and writes the resolution graph of its last move as DOT.
+func runTrace(args []string) {
+	fs := flag.NewFlagSet("trace", flag.ExitOnError)
+	out := fs.String("o", "", "output file, stdout when empty")
+	moves := fs.String("moves", "", "replay to apply, written as u, d, l and r letters, the last move is traced")
+	fs.Parse(args)
+
+	path := defaultLevel
+	if fs.NArg() > 0 {
+		path = fs.Arg(0)
+	}
+
+	g := game.NewGame(newLogger())
+	err := g.Parse(loadLevel(path))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	replay, err := game.ParseMoves(*moves)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(replay) == 0 {
+		log.Fatal("trace needs at least one move")
+	}
+
+	for _, dir := range replay[:len(replay)-1] {
+		g.Move(dir)
+	}
+	dot := g.MoveTrace(replay[len(replay)-1]).DOT()
+
+	if *out == "" {
+		fmt.Print(dot)
+		return
+	}
+	err = os.WriteFile(*out, []byte(dot), 0644)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -1,6 +1,7 @@
 package game
 
 import (
+	"fmt"
 	"slimesolver/game/math"
 	"strings"
 )
@@ -11,6 +12,10 @@ type StateChange struct {
 	Updates  []Actor
 	Watching []Actor
 	Message  string
+	// Push is the strength of the slimes pushing in a line up to this actor,
+	// and Load the weight of the crates they push up to it
+	Push int
+	Load int
 }
 
 type AffectingStates struct {
@@ -40,6 +45,7 @@ type Actor interface {
 	Tick(g *Game)
 	Solid() bool
 	Damage(g *Game)
+	Clone() Actor
 }
 
 func (s StateChange) String() string {
@@ -69,6 +75,13 @@ func (s StateChange) String() string {
 		sb.WriteString("]")
 	}
 
+	if s.Load > 0 {
+		if sb.Len() > 0 {
+			sb.WriteString(" ")
+		}
+		sb.WriteString(fmt.Sprintf("P: %d/%d", s.Push, s.Load))
+	}
+
 	if s.Watching != nil && len(s.Watching) > 0 {
 		if sb.Len() > 0 {
 			sb.WriteString(" ")
@@ -104,6 +117,9 @@ func (s StateChange) Equals(other StateChange) bool {
 	if !s.From.Equals(other.From) {
 		return false
 	}
+	if s.Push != other.Push || s.Load != other.Load {
+		return false
+	}
 
 	return s.Move.Equals(other.Move)
 }
@@ -113,7 +129,12 @@ type PositionComponent struct {
 }
 
 func canMoveTo(g *Game, pos math.Vector2, self Actor) bool {
-	if g.GetTokenAt(pos.X, pos.Y) == WallToken {
+	if g.IsWallOrEdge(pos.X, pos.Y) {
+		return false
+	}
+
+	// gates are only entered from the tile behind their arrow
+	if from := self.GetPosition(); !from.Equals(pos) && g.IsBlocked(pos, directionBetween(from, pos)) {
 		return false
 	}
 
@@ -133,19 +154,19 @@ func canMoveTo(g *Game, pos math.Vector2, self Actor) bool {
 }
 
 func (p *PositionComponent) GetPosition() math.Vector2 {
-	return math.Vector2{p.X, p.Y}
+	return math.Vector2{X: p.X, Y: p.Y}
 }
 
 func moveVector(vec math.Vector2, dir Direction) math.Vector2 {
 	switch dir {
 	case Up:
-		return math.Vector2{vec.X, vec.Y - 1}
+		return math.Vector2{X: vec.X, Y: vec.Y - 1}
 	case Down:
-		return math.Vector2{vec.X, vec.Y + 1}
+		return math.Vector2{X: vec.X, Y: vec.Y + 1}
 	case Left:
-		return math.Vector2{vec.X - 1, vec.Y}
+		return math.Vector2{X: vec.X - 1, Y: vec.Y}
 	case Right:
-		return math.Vector2{vec.X + 1, vec.Y}
+		return math.Vector2{X: vec.X + 1, Y: vec.Y}
 	default:
 		return vec
 	}
@@ -1,16 +1,51 @@
 package game
 
+import "slimesolver/game/math"
+
+// weights of crates, with the default rules a small slime pushes a light crate
+// on its own, a big slime a crate and two big slimes a heavy crate
+const (
+	lightWeight = 1
+	crateWeight = 2
+	heavyWeight = 4
+)
+
 type Box struct {
 	PositionComponent
+	weight int
 }
 
 func NewBox(x, y int) *Box {
 	return &Box{
 		PositionComponent: PositionComponent{x, y},
+		weight:            crateWeight,
+	}
+}
+
+// newBoxOf creates the crate written as token.
+func newBoxOf(x, y int, token Token) *Box {
+	b := NewBox(x, y)
+	switch token {
+	case LightBoxToken:
+		b.weight = lightWeight
+	case HeavyBoxToken:
+		b.weight = heavyWeight
 	}
+	return b
+}
+
+// IsBoxToken reports whether token is a crate of any weight.
+func IsBoxToken(token Token) bool {
+	return token == BoxToken || token == LightBoxToken || token == HeavyBoxToken
 }
 
 func (b *Box) Token() Token {
+	switch b.weight {
+	case lightWeight:
+		return LightBoxToken
+	case heavyWeight:
+		return HeavyBoxToken
+	}
 	return BoxToken
 }
 
@@ -20,17 +55,38 @@ func (b *Box) String() string {
 
 func (b *Box) Transform(g *Game, dir Direction, affectingStates AffectingStates) (*StateChange, Actor) {
 	pos := b.GetPosition()
-	// something pushing the box
+	// something pushing the box, conveyors only carry things into free tiles.
+	// The pusher passes on the strength of the slimes behind it and the weight of
+	// the crates they push, so the crates of a line move together or not at all.
 	for actor, change := range affectingStates.OnToStates {
-		token := actor.Token()
-		switch token {
-		case SlimeToken:
-			dir = directionBetween(change.From, b.GetPosition())
-			move := moveVector(pos, dir)
-			return &StateChange{
-				Move: move,
-			}, actor
+		if change.Push == 0 || g.conveying {
+			continue
+		}
+		dir = directionBetween(change.From, b.GetPosition())
+		move := moveVector(pos, dir)
+		ahead, free := g.crates(pos, dir)
+		load := change.Load + ahead
+		// walls, gates pointing another way and crates too heavy for the slimes
+		// stop the box and whoever pushes it
+		if !free || change.Push < load {
+			move = pos
+		}
+		return &StateChange{
+			Move: move,
+			Push: change.Push,
+			Load: change.Load + b.weight,
+		}, actor
+	}
+
+	// a box sliding on ice or carried by a conveyor keeps going, things moving onto it wait for it
+	if step, ok := g.moving[b]; ok {
+		var parent Actor
+		for actor := range affectingStates.OnToStates {
+			parent = actor
 		}
+		return &StateChange{
+			Move: moveVector(pos, step),
+		}, parent
 	}
 
 	// otherwise slime stands still
@@ -66,3 +122,30 @@ func (b *Box) Solid() bool {
 func (b *Box) Damage(g *Game) {
 
 }
+
+func (b *Box) Clone() Actor {
+	c := *b
+	return &c
+}
+
+// crates returns the weight of the crates in a line from pos in dir,
+// and whether every one of them can move a tile further.
+func (g *Game) crates(pos math.Vector2, dir Direction) (int, bool) {
+	weight := 0
+	for {
+		var box *Box
+		for _, actor := range g.GetActors(pos) {
+			if b, ok := actor.(*Box); ok {
+				box = b
+			}
+		}
+		if box == nil {
+			return weight, true
+		}
+		weight += box.weight
+		pos = moveVector(pos, dir)
+		if g.IsBlocked(pos, dir) {
+			return weight, false
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package game
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestBoxes(t *testing.T) {
 	tt := []testCase{
@@ -109,3 +112,119 @@ func TestBoxWithPit(t *testing.T) {
 		want:   `...@`,
 	})
 }
+
+func TestBoxOffEdge(t *testing.T) {
+	testGame(t, testCase{
+		name:   "push box off the edge",
+		state:  `@B`,
+		inputs: []Direction{Right},
+		want:   `@B`,
+	})
+}
+
+func TestPushStrength(t *testing.T) {
+	strong := &Rules{PushStrength: 2}
+	tt := []testCase{
+		{
+			name:   "two slimes push two crates",
+			state:  `@@BB.`,
+			inputs: []Direction{Right},
+			want:   `.@@BB`,
+		},
+		{
+			name:   "two slimes can't push three crates",
+			state:  `@@BBB.`,
+			inputs: []Direction{Right},
+			want:   `@@BBB.`,
+		},
+		{
+			name:   "line of crates against a wall",
+			state:  `@@BB#`,
+			inputs: []Direction{Right},
+			want:   `@@BB#`,
+		},
+		{
+			name:   "small slime pushes a light crate",
+			state:  `ob.`,
+			inputs: []Direction{Right},
+			want:   `.ob`,
+		},
+		{
+			name:   "small slime can't push two light crates",
+			state:  `obb.`,
+			inputs: []Direction{Right},
+			want:   `obb.`,
+		},
+		{
+			name:   "small slimes follow a light crate",
+			state:  `oob.`,
+			inputs: []Direction{Right},
+			want:   `.oob`,
+		},
+		{
+			name:   "small slimes don't pass on strength",
+			state:  `@oB.`,
+			inputs: []Direction{Right},
+			want:   `@oB.`,
+		},
+		{
+			name:   "slime pushes two light crates",
+			state:  `@bb.`,
+			inputs: []Direction{Right},
+			want:   `.@bb`,
+		},
+		{
+			name:   "slime can't push a heavy crate",
+			state:  `@H.`,
+			inputs: []Direction{Right},
+			want:   `@H.`,
+		},
+		{
+			name:   "two slimes push a heavy crate",
+			state:  `@@H.`,
+			inputs: []Direction{Right},
+			want:   `.@@H`,
+		},
+		{
+			name:   "heavy crate fills a pit",
+			state:  `@@HO.`,
+			inputs: []Direction{Right, Right},
+			want:   `..@@.`,
+		},
+		{
+			name:   "strong slime pushes two crates",
+			state:  `@BB.`,
+			inputs: []Direction{Right},
+			want:   `.@BB`,
+			rules:  strong,
+		},
+		{
+			name:   "strong slime pushes a heavy crate",
+			state:  `@H.`,
+			inputs: []Direction{Right},
+			want:   `.@H`,
+			rules:  strong,
+		},
+		{
+			name:   "strong small slime pushes a crate",
+			state:  `oB.`,
+			inputs: []Direction{Right},
+			want:   `.oB`,
+			rules:  strong,
+		},
+		{
+			name:   "very strong slime pushes a long line of crates",
+			state:  "; push-strength = 100\n@" + strings.Repeat("B", 24) + "..",
+			inputs: []Direction{Right},
+			want:   ".@" + strings.Repeat("B", 24) + ".",
+		},
+		{
+			name:   "long line of slimes pushes as many crates",
+			state:  strings.Repeat("@", 30) + strings.Repeat("B", 30) + ".",
+			inputs: []Direction{Right},
+			want:   "." + strings.Repeat("@", 30) + strings.Repeat("B", 30),
+		},
+	}
+
+	testCases(t, tt)
+}
@@ -0,0 +1,134 @@
+package game
+
+import (
+	"log/slog"
+	"slimesolver/game/math"
+)
+
+// Conveyor returns the direction of the conveyor at x, y, or false if there is none.
+func (g *Game) Conveyor(x, y int) (Direction, bool) {
+	return conveyorDirection(g.GetTokenAt(x, y))
+}
+
+// conveyorDirection returns the direction a conveyor token carries actors in.
+func conveyorDirection(token Token) (Direction, bool) {
+	switch token {
+	case ConveyorUpToken:
+		return Up, true
+	case ConveyorDownToken:
+		return Down, true
+	case ConveyorLeftToken:
+		return Left, true
+	case ConveyorRightToken:
+		return Right, true
+	}
+	return Zero, false
+}
+
+// IsConveyorToken reports whether token is a conveyor.
+func IsConveyorToken(token Token) bool {
+	_, ok := conveyorDirection(token)
+	return ok
+}
+
+// convey carries every slime and box standing on a conveyor one tile in its direction,
+// once the actors moved and slid. It is a step of its own, so a line of actors on conveyors
+// moves up like a queue, arriving actors press switches and carried small slimes combine,
+// and pits and closed doors take them when the actors tick afterwards.
+// Carried actors don't push anything. Actors carried onto the same tile and actors
+// carried around a loop of conveyors block each other and stay where they are,
+// otherwise who moves first would depend on the order of the actors. Only slimes
+// carried onto the same tile and two slimes carried into each other meet instead,
+// the way slimes walking onto a tile do, see Slime.meet.
+func (g *Game) convey(trace *Trace) {
+	// slimes that combined don't block the ones carried onto their tile
+	g.removeKilled()
+
+	carried := make(map[Actor]Direction)
+	for _, actor := range g.actors {
+		if _, ok := actor.(positioner); !ok {
+			continue
+		}
+		pos := actor.GetPosition()
+		if dir, ok := g.Conveyor(pos.X, pos.Y); ok {
+			if !g.IsBlocked(moveVector(pos, dir), dir) {
+				carried[actor] = dir
+			}
+		}
+	}
+
+	arriving := make(map[math.Vector2][]Actor)
+	for actor, dir := range carried {
+		to := moveVector(actor.GetPosition(), dir)
+		arriving[to] = append(arriving[to], actor)
+	}
+	for _, actors := range arriving {
+		if len(actors) > 1 && !allSlimes(actors) {
+			for _, actor := range actors {
+				delete(carried, actor)
+			}
+		}
+	}
+
+	at := make(map[math.Vector2]Actor, len(carried))
+	for actor := range carried {
+		at[actor.GetPosition()] = actor
+	}
+	for _, loop := range conveyorLoops(carried, at) {
+		if len(loop) == 2 && isSlime(loop[0]) && isSlime(loop[1]) {
+			continue
+		}
+		for _, actor := range loop {
+			delete(carried, actor)
+		}
+	}
+
+	if len(carried) == 0 {
+		return
+	}
+	if g.logEnabled(slog.LevelDebug) {
+		g.logAttrs(slog.LevelDebug, "convey", slog.Int("turn", g.turn), slog.Int("actors", len(carried)))
+	}
+	g.moving = carried
+	g.conveying = true
+	g.resolve(Zero, trace)
+	g.moving = nil
+	g.conveying = false
+}
+
+// allSlimes reports whether every one of actors is a slime.
+func allSlimes(actors []Actor) bool {
+	for _, actor := range actors {
+		if !isSlime(actor) {
+			return false
+		}
+	}
+	return true
+}
+
+// conveyorLoops finds the carried actors that wait for each other in a circle.
+// at holds the carried actor on each tile.
+func conveyorLoops(carried map[Actor]Direction, at map[math.Vector2]Actor) [][]Actor {
+	var loops [][]Actor
+	done := make(map[Actor]bool)
+	for start := range carried {
+		if done[start] {
+			continue
+		}
+		var path []Actor
+		seen := make(map[Actor]int)
+		for actor := start; actor != nil && !done[actor]; {
+			if i, ok := seen[actor]; ok {
+				loops = append(loops, path[i:])
+				break
+			}
+			seen[actor] = len(path)
+			path = append(path, actor)
+			actor = at[moveVector(actor.GetPosition(), carried[actor])]
+		}
+		for _, actor := range path {
+			done[actor] = true
+		}
+	}
+	return loops
+}
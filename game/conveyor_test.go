@@ -0,0 +1,94 @@
+package game
+
+import "testing"
+
+func TestConveyor(t *testing.T) {
+	tt := []testCase{
+		{
+			name:   "carry one tile",
+			state:  `@>..`,
+			inputs: []Direction{Right},
+			want:   `.>@.`,
+		},
+		{
+			name:   "stop at the edge",
+			state:  `@>`,
+			inputs: []Direction{Right},
+			want:   `.@`,
+		},
+		{
+			name:   "carry a box",
+			state:  `@B>.`,
+			inputs: []Direction{Right},
+			want:   `.@>B`,
+		},
+		{
+			name:   "queue up",
+			state:  "@@.\n>>.",
+			inputs: []Direction{Down},
+			want:   "...\n>@@",
+		},
+		{
+			name:   "blocked queue",
+			state:  "@@.\n>>#",
+			inputs: []Direction{Down},
+			want:   "...\n@@#",
+		},
+		{
+			name:   "carried onto the same tile",
+			state:  "@.@\n>.<",
+			inputs: []Direction{Down},
+			want:   "...\n>@@",
+		},
+		{
+			name:   "carried into each other",
+			state:  "@@\n><",
+			inputs: []Direction{Down},
+			want:   "..\n@@",
+		},
+		{
+			name:   "don't push boxes",
+			state:  "@..\n>B.",
+			inputs: []Direction{Down},
+			want:   "...\n@B.",
+		},
+		{
+			name:   "small slimes combine",
+			state:  "o.\n>o",
+			inputs: []Direction{Down},
+			want:   "..\n>@",
+		},
+		{
+			name:   "slime falls into a pit",
+			state:  `@>O`,
+			inputs: []Direction{Right},
+			want:   `.>O`,
+		},
+		{
+			name:   "box fills a pit",
+			state:  `@B>O`,
+			inputs: []Direction{Right},
+			want:   `.@>.`,
+		},
+		{
+			name:   "press a switch on arrival",
+			state:  `@>xD`,
+			inputs: []Direction{Right},
+			want:   `.>@_`,
+		},
+		{
+			name:   "crushed when the door closes",
+			state:  "@x.\n@>D",
+			inputs: []Direction{Right, Down},
+			want:   ".x.\n.@D",
+		},
+		{
+			name:   "carried onto a teleporter",
+			state:  `@>1.1`,
+			inputs: []Direction{Right},
+			want:   `.>1.@`,
+		},
+	}
+
+	testCases(t, tt)
+}
@@ -0,0 +1,29 @@
+package game
+
+import (
+	"log/slog"
+	"slimesolver/game/math"
+)
+
+func (g *Game) IsCrumbling(x, y int) bool {
+	return g.GetTokenAt(x, y) == CrumblingToken
+}
+
+// crumble turns the crumbling floor actors stepped off during the move into pits,
+// once nothing stands on it anymore. An actor that steps onto a tile another one
+// left in the same move holds it up until it leaves too.
+// It runs before the actors tick, so a box can fill the pit in a later move.
+func (g *Game) crumble(from map[Actor]math.Vector2) {
+	for actor, start := range from {
+		if start.Equals(actor.GetPosition()) || !g.IsCrumbling(start.X, start.Y) {
+			continue
+		}
+		if len(g.GetActors(start)) > 0 {
+			continue
+		}
+		if g.logEnabled(slog.LevelDebug) {
+			g.logAttrs(slog.LevelDebug, "crumble", slog.Int("turn", g.turn), slog.String("pos", start.String()))
+		}
+		g.SetTokenAt(start.X, start.Y, PitToken)
+	}
+}
@@ -0,0 +1,81 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCrumble(t *testing.T) {
+	tt := []testCase{
+		{
+			name:   "hold while standing",
+			state:  `@,.`,
+			inputs: []Direction{Right},
+			want:   `.@.`,
+		},
+		{
+			name:   "crumble when stepped off",
+			state:  `@,.`,
+			inputs: []Direction{Right, Right},
+			want:   `.O@`,
+		},
+		{
+			name:   "fall in on the way back",
+			state:  `@,.`,
+			inputs: []Direction{Right, Right, Left},
+			want:   `.O.`,
+		},
+		{
+			name:   "held by the next slime",
+			state:  `@@,.`,
+			inputs: []Direction{Right, Right},
+			want:   `..@@`,
+		},
+		{
+			name:   "crumble once the pusher leaves",
+			state:  `@B,.#`,
+			inputs: []Direction{Right, Right, Left},
+			want:   `.@OB#`,
+		},
+		{
+			name: "box fills the crumbled floor",
+			state: `@,.
+					.B.
+					...`,
+			inputs: []Direction{Right, Right, Down, Down, Left, Up},
+			want: `...
+				   .@.
+				   ...`,
+		},
+	}
+
+	testCases(t, tt)
+}
+
+func TestCrumbleUndo(t *testing.T) {
+	g := NewGame(testLogger(t))
+	if err := g.Parse(`@,.`); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	g.Play(Right)
+	c := g.Clone()
+	g.Play(Right)
+	if strings.TrimSpace(c.String()) != `.@.` {
+		t.Fatalf("expected the clone to keep its floor, got\n%s", c.String())
+	}
+	for state, same := range map[string]bool{`.O@`: true, `.,@`: false} {
+		p := NewGame(testLogger(t))
+		if err := p.Parse(state); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if (p.layout() == g.layout()) != same {
+			t.Fatalf("expected the crumbled floor to be part of the key, got %q and %q", p.layout(), g.layout())
+		}
+	}
+
+	g.Undo()
+	if !g.IsCrumbling(1, 0) {
+		t.Fatalf("expected undo to restore the floor, got\n%s", g.String())
+	}
+}
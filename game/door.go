@@ -29,6 +29,10 @@ func (d *Door) Transform(g *Game, dir Direction, affectingStates AffectingStates
 		Move:    math.NegVec,
 		Message: "close",
 	}
+	// doors opened earlier in the move stay open while actors slide or are carried
+	if g.moving != nil && d.open {
+		nextChange.Message = "open"
+	}
 	var parent Actor
 
 	// switch activated by something
@@ -58,9 +62,15 @@ func (d *Door) Tick(g *Game) {
 	if !d.open {
 		actors := g.GetActors(d.GetPosition())
 		for _, actor := range actors {
-			if actor != d {
-				g.Kill(actor)
+			if actor == d {
+				continue
+			}
+			// a door that doesn't crush stays open until nothing is under it
+			if !g.rules.DoorsCrush {
+				d.open = true
+				return
 			}
+			g.Kill(actor)
 		}
 	}
 }
@@ -72,3 +82,8 @@ func (d *Door) Solid() bool {
 func (d *Door) Damage(g *Game) {
 
 }
+
+func (d *Door) Clone() Actor {
+	c := *d
+	return &c
+}
@@ -2,6 +2,7 @@ package game
 
 import (
 	"fmt"
+	"log/slog"
 	"slimesolver/game/math"
 	"sort"
 	"strings"
@@ -15,7 +16,11 @@ const (
 	PitToken          Token = 'O'
 	SlimeToken        Token = '@'
 	SmallSlimeToken         = 'o'
+	LargeSlimeToken   Token = '&'
+	HugeSlimeToken    Token = '%'
 	BoxToken          Token = 'B'
+	LightBoxToken     Token = 'b'
+	HeavyBoxToken     Token = 'H'
 	SwitchToken       Token = 'x'
 	ClosedDoorToken   Token = 'D'
 	OpenDoorToken     Token = '_'
@@ -23,6 +28,21 @@ const (
 	SpikeDownToken    Token = '-'
 	PusherToken       Token = '='
 	PusherActiveToken Token = '['
+	GoalToken         Token = '*'
+	KeyToken          Token = 'k'
+	LockedDoorToken   Token = 'L'
+	IceToken          Token = '~'
+	CrumblingToken    Token = ','
+
+	ConveyorUpToken    Token = 'A'
+	ConveyorDownToken  Token = 'v'
+	ConveyorLeftToken  Token = '<'
+	ConveyorRightToken Token = '>'
+
+	GateUpToken    Token = 'u'
+	GateDownToken  Token = 'd'
+	GateLeftToken  Token = 'l'
+	GateRightToken Token = 'r'
 )
 
 type Direction int
@@ -35,6 +55,10 @@ const (
 	Right
 )
 
+func (d Direction) String() string {
+	return dirString(d)
+}
+
 func dirString(dir Direction) string {
 	switch dir {
 	case Up:
@@ -60,25 +84,55 @@ type Game struct {
 
 	killQueue []Actor
 
-	logging bool
+	// partner of every teleporter pad, pads never change so clones share it
+	teleporters map[math.Vector2]math.Vector2
+
+	// actors moving in the current step of a move and where to, nil when every slime
+	// moves in the direction of the move. Later steps slide actors on ice or carry them on conveyors.
+	moving map[Actor]Direction
+	// conveying is set while conveyors carry actors, carried actors don't push
+	conveying bool
+
+	rules Rules
+
+	// number of moves made since the level was parsed
+	turn int
+
+	// snapshots taken by Play before each move, used by Undo
+	history []*Game
+	moves   []Direction
+
+	log *slog.Logger
 }
 
-func NewGame(logging bool) *Game {
+// NewGame creates a game that writes debug and trace records to logger.
+// A nil logger turns logging off.
+func NewGame(logger *slog.Logger) *Game {
+	if logger == nil {
+		logger = discardLogger
+	}
 	return &Game{
-		logging: logging,
+		rules: DefaultRules(),
+		log:   logger,
 	}
 }
 
-func (g *Game) Println(args ...interface{}) {
-	if g.logging {
-		fmt.Println(args...)
-	}
+// Turn returns the number of moves made since the level was parsed.
+func (g *Game) Turn() int {
+	return g.turn
 }
 
-func (g *Game) Printf(format string, args ...interface{}) {
-	if g.logging {
-		fmt.Printf(format, args...)
+// Width returns the number of columns on the board.
+func (g *Game) Width() int {
+	if len(g.board) == 0 {
+		return 0
 	}
+	return len(g.board[0])
+}
+
+// Height returns the number of rows on the board.
+func (g *Game) Height() int {
+	return len(g.board)
 }
 
 func (g *Game) GetTokenAt(x, y int) Token {
@@ -101,10 +155,47 @@ func (g *Game) IsPit(x, y int) bool {
 	return g.GetTokenAt(x, y) == PitToken
 }
 
+func (g *Game) IsGoal(x, y int) bool {
+	return g.GetTokenAt(x, y) == GoalToken
+}
+
+// Won reports whether the level is solved, which is when every goal has a slime on it.
+// Levels without goals can't be won.
+func (g *Game) Won() bool {
+	goals := 0
+	for y, row := range g.board {
+		for x, token := range row {
+			if token != GoalToken {
+				continue
+			}
+			goals++
+
+			found := false
+			for _, actor := range g.GetActors(math.Vector2{X: x, Y: y}) {
+				if isSlime(actor) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+	}
+	return goals > 0
+}
+
 func (g *Game) AddActor(actor Actor) {
 	g.actors = append(g.actors, actor)
 }
 
+// Actors returns a copy of the actors on the board in the order they are updated.
+func (g *Game) Actors() []Actor {
+	l := make([]Actor, len(g.actors))
+	copy(l, g.actors)
+	return l
+}
+
 func (g *Game) GetActors(pos math.Vector2) []Actor {
 	l := make([]Actor, 0)
 	for _, entity := range g.actors {
@@ -129,7 +220,23 @@ func (g *Game) GetActorsWithTokens(tokens []Token) []Actor {
 	return l
 }
 
+// before reports whether a comes before b in the order of the actors.
+func (g *Game) before(a, b Actor) bool {
+	for _, actor := range g.actors {
+		switch actor {
+		case a:
+			return true
+		case b:
+			return false
+		}
+	}
+	return false
+}
+
 func (g *Game) Kill(actor Actor) {
+	if g.logEnabled(slog.LevelDebug) {
+		g.logAttrs(slog.LevelDebug, "kill", slog.Int("turn", g.turn), g.actorAttr(actor))
+	}
 	g.killQueue = append(g.killQueue, actor)
 }
 
@@ -142,16 +249,17 @@ func (g *Game) RemoveActor(actor Actor) {
 	}
 }
 
-func getPriorityToken(actors []Actor) Token {
+// PriorityToken returns the token that is drawn when several actors share a tile.
+func PriorityToken(actors []Actor) Token {
 	var token Token
 	priority := -1
 	for _, actor := range actors {
 		t := actor.Token()
 		p := 0
 		switch t {
-		case SlimeToken:
+		case SlimeToken, LargeSlimeToken, HugeSlimeToken:
 			p = 10
-		case BoxToken:
+		case BoxToken, LightBoxToken, HeavyBoxToken:
 			p = 5
 		default:
 			p = 0
@@ -168,9 +276,9 @@ func (g *Game) String() string {
 	var sb strings.Builder
 	for y, row := range g.board {
 		for x, token := range row {
-			actors := g.GetActors(math.Vector2{x, y})
+			actors := g.GetActors(math.Vector2{X: x, Y: y})
 			if len(actors) > 0 {
-				token = getPriorityToken(actors)
+				token = PriorityToken(actors)
 			}
 			sb.WriteRune(rune(token))
 		}
@@ -187,7 +295,13 @@ func cleanState(state string) string {
 	return state
 }
 
+// Parse reads a level, the rule lines at its top and the board below them.
 func (g *Game) Parse(state string) error {
+	rules, state, err := ParseRules(state)
+	if err != nil {
+		return err
+	}
+	g.rules = rules
 	state = cleanState(state)
 
 	lines := strings.Split(state, "\n")
@@ -202,6 +316,10 @@ func (g *Game) Parse(state string) error {
 
 	// initialize actors
 	g.actors = make([]Actor, 0)
+	g.killQueue = make([]Actor, 0)
+	g.turn = 0
+	g.history = nil
+	g.moves = nil
 
 	for y, line := range lines {
 		if len(line) != width {
@@ -217,28 +335,46 @@ func (g *Game) Parse(state string) error {
 				g.board[y][x] = EmptyToken
 			case PitToken:
 				g.board[y][x] = PitToken
-			case SlimeToken, SmallSlimeToken:
+			case GoalToken:
+				g.board[y][x] = GoalToken
+			case IceToken, CrumblingToken, ConveyorUpToken, ConveyorDownToken, ConveyorLeftToken, ConveyorRightToken,
+				GateUpToken, GateDownToken, GateLeftToken, GateRightToken:
+				g.board[y][x] = Token(c)
+			case SlimeToken, SmallSlimeToken, LargeSlimeToken, HugeSlimeToken:
+				size := SlimeSize(Token(c))
+				if size > g.rules.SlimeSize {
+					return fmt.Errorf("slime of size %d is bigger than slime-size %d", size, g.rules.SlimeSize)
+				}
 				g.board[y][x] = EmptyToken
-				g.actors = append(g.actors, NewSlime(x, y, Token(c) == SmallSlimeToken))
-			case BoxToken:
+				g.actors = append(g.actors, NewSizedSlime(x, y, size))
+			case BoxToken, LightBoxToken, HeavyBoxToken:
 				g.board[y][x] = EmptyToken
-				g.actors = append(g.actors, NewBox(x, y))
+				g.actors = append(g.actors, newBoxOf(x, y, Token(c)))
 			case SwitchToken:
 				g.board[y][x] = EmptyToken
 				g.actors = append(g.actors, NewSwitch(x, y))
 			case ClosedDoorToken:
 				g.board[y][x] = EmptyToken
 				g.actors = append(g.actors, NewDoor(x, y))
+			case KeyToken:
+				g.board[y][x] = EmptyToken
+				g.actors = append(g.actors, NewKey(x, y))
+			case LockedDoorToken:
+				g.board[y][x] = EmptyToken
+				g.actors = append(g.actors, NewLockedDoor(x, y))
 			case SpikeUpToken, SpikeDownToken:
 				g.board[y][x] = EmptyToken
 				g.actors = append(g.actors, NewSpike(x, y, Token(c) == SpikeUpToken))
 			default:
-				return fmt.Errorf("invalid token: %c", c)
+				if !IsTeleporterToken(Token(c)) {
+					return fmt.Errorf("invalid token: %c", c)
+				}
+				g.board[y][x] = Token(c)
 			}
 		}
 	}
 
-	return nil
+	return g.pairTeleporters()
 }
 
 type StateList map[Actor]*StateChangeNode
@@ -419,49 +555,97 @@ func popLeaves(states StateList) []StateChangeLeaf {
 }
 
 func (g *Game) Move(dir Direction) {
+	g.move(dir, nil)
+}
+
+// MoveTrace is Move but it records every step taken to resolve the turn.
+func (g *Game) MoveTrace(dir Direction) *Trace {
+	trace := newTrace(g, dir)
+	g.move(dir, trace)
+	return trace
+}
+
+func (g *Game) move(dir Direction, trace *Trace) {
+	g.turn++
+	if g.logEnabled(slog.LevelDebug) {
+		g.logAttrs(slog.LevelDebug, "move", slog.Int("turn", g.turn), slog.String("dir", dirString(dir)))
+	}
+
+	from := g.positions()
+	g.resolve(dir, trace)
+	g.slide(dir, from, trace)
+	g.convey(trace)
+	g.teleport(from)
+	g.crumble(from)
+
+	for _, actor := range g.actors {
+		actor.Tick(g)
+	}
+
+	g.removeKilled()
+}
+
+// positions returns where every actor is.
+func (g *Game) positions() map[Actor]math.Vector2 {
+	l := make(map[Actor]math.Vector2, len(g.actors))
+	for _, actor := range g.actors {
+		l[actor] = actor.GetPosition()
+	}
+	return l
+}
+
+func (g *Game) removeKilled() {
+	for _, actor := range g.killQueue {
+		g.RemoveActor(actor)
+	}
+	g.killQueue = make([]Actor, 0)
+}
+
+// resolve builds the StateChangeNode graph for one step in dir and applies it from the leaves up.
+func (g *Game) resolve(dir Direction, trace *Trace) {
+	// a line of actors pushing each other grows the graph by about one actor a step
+	maxSteps := 25 + len(g.actors)
 	states := make(StateList, 0)
 	step := 1
 	changed := true
 	for changed {
-		if step > 25 {
+		if step > maxSteps {
 			panic("too many steps")
 		}
 
-		g.Println("calculating new states step: ", step)
-		step++
-		changed = mergeStates(states, g.getNextStates(states, dir))
-		if changed {
-			leaves := getLeaves(states)
-			for _, node := range leaves {
-				g.Println(node)
+		newStates := g.getNextStates(states, dir)
+		changed = mergeStates(states, newStates)
+		if trace != nil {
+			trace.addIteration(newStates, states, changed)
+		}
+		if g.logEnabled(LevelTrace) {
+			g.logAttrs(LevelTrace, "resolve", slog.Int("turn", g.turn), slog.Int("step", step), slog.Bool("changed", changed))
+			if changed {
+				leaves := getLeaves(states)
+				for _, node := range leaves {
+					g.logAttrs(LevelTrace, "leaf", slog.Int("turn", g.turn), slog.Int("step", step),
+						g.actorAttr(node.Actor), slog.String("change", node.Change.String()), slog.Int("depth", node.Depth))
+				}
 			}
-		} else {
-			g.Println("no new states")
 		}
-
-		g.Println("----------")
+		step++
 	}
 
 	step = 0
 	for hasLeaves(states) {
-		g.Println("apply states step: ", step)
-		step++
 		leaves := popLeaves(states)
+		if trace != nil {
+			trace.addWave(leaves)
+		}
 		for _, leaf := range leaves {
 			change := leaf.Node.Change
 			actor := leaf.Actor
-			g.Println("apply state:", change, " to actor:", actor)
+			if g.logEnabled(LevelTrace) {
+				g.logAttrs(LevelTrace, "apply", slog.Int("turn", g.turn), slog.Int("wave", step),
+					g.actorAttr(actor), slog.String("change", change.String()))
+			}
 			actor.Apply(g, change)
 		}
-		g.Println("----------")
-	}
-
-	for _, actor := range g.actors {
-		actor.Tick(g)
-	}
-
-	for _, actor := range g.killQueue {
-		g.RemoveActor(actor)
+		step++
 	}
-	g.killQueue = make([]Actor, 0)
 }
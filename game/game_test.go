@@ -2,10 +2,25 @@ package game
 
 import (
 	"fmt"
+	"log/slog"
 	"strings"
 	"testing"
 )
 
+// testLogger writes every record to the test log, it is shown with -v or when a test fails
+func testLogger(t *testing.T) *slog.Logger {
+	return slog.New(slog.NewTextHandler(testWriter{t}, &slog.HandlerOptions{Level: LevelTrace}))
+}
+
+type testWriter struct {
+	t *testing.T
+}
+
+func (w testWriter) Write(p []byte) (int, error) {
+	w.t.Log(strings.TrimSpace(string(p)))
+	return len(p), nil
+}
+
 func TestBasicBoard(t *testing.T) {
 	tt := []struct {
 		name  string
@@ -50,7 +65,7 @@ func TestBasicBoard(t *testing.T) {
 			// we add whitespace for readability
 			state := cleanState(tc.state)
 
-			g := NewGame(true)
+			g := NewGame(testLogger(t))
 			err := g.Parse(state)
 			if tc.err && err == nil {
 				t.Fatalf("expected error, got nil")
@@ -76,6 +91,8 @@ type testCase struct {
 	state  string
 	inputs []Direction
 	want   string
+	// rules the level is played with, the default rules when nil
+	rules *Rules
 }
 
 func testCases(t *testing.T, cases []testCase) {
@@ -88,11 +105,14 @@ func testGame(t *testing.T, tc testCase) {
 	t.Run(tc.name, func(t *testing.T) {
 		state := cleanState(tc.state)
 
-		g := NewGame(true)
+		g := NewGame(testLogger(t))
 		err := g.Parse(state)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
+		if tc.rules != nil {
+			g.SetRules(*tc.rules)
+		}
 		fmt.Println(g.String())
 
 		for _, dir := range tc.inputs {
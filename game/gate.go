@@ -0,0 +1,40 @@
+package game
+
+import "slimesolver/game/math"
+
+// Gate returns the direction actors have to move in to enter the one-way gate at x, y,
+// or false if there is none.
+func (g *Game) Gate(x, y int) (Direction, bool) {
+	return gateDirection(g.GetTokenAt(x, y))
+}
+
+// gateDirection returns the direction a gate token lets actors in.
+func gateDirection(token Token) (Direction, bool) {
+	switch token {
+	case GateUpToken:
+		return Up, true
+	case GateDownToken:
+		return Down, true
+	case GateLeftToken:
+		return Left, true
+	case GateRightToken:
+		return Right, true
+	}
+	return Zero, false
+}
+
+// IsGateToken reports whether token is a one-way gate.
+func IsGateToken(token Token) bool {
+	_, ok := gateDirection(token)
+	return ok
+}
+
+// IsBlocked reports whether an actor moving in dir can't enter pos,
+// because it is a wall, off the board or a gate pointing another way.
+func (g *Game) IsBlocked(pos math.Vector2, dir Direction) bool {
+	if g.IsWallOrEdge(pos.X, pos.Y) {
+		return true
+	}
+	gate, ok := g.Gate(pos.X, pos.Y)
+	return ok && gate != dir
+}
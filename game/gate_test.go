@@ -0,0 +1,64 @@
+package game
+
+import "testing"
+
+func TestGate(t *testing.T) {
+	tt := []testCase{
+		{
+			name:   "enter along the arrow",
+			state:  `@r.`,
+			inputs: []Direction{Right},
+			want:   `.@.`,
+		},
+		{
+			name:   "blocked against the arrow",
+			state:  `.r@`,
+			inputs: []Direction{Left},
+			want:   `.r@`,
+		},
+		{
+			name:   "blocked from the side",
+			state:  `@u`,
+			inputs: []Direction{Right},
+			want:   `@u`,
+		},
+		{
+			name:   "leave any way",
+			state:  `@r.`,
+			inputs: []Direction{Right, Left},
+			want:   `@r.`,
+		},
+		{
+			name:   "slimes behind wait",
+			state:  `@@l.`,
+			inputs: []Direction{Right},
+			want:   `@@l.`,
+		},
+		{
+			name:   "push a box along the arrow",
+			state:  `@Br.`,
+			inputs: []Direction{Right},
+			want:   `.@B.`,
+		},
+		{
+			name:   "push a box against the arrow",
+			state:  `@Bl.`,
+			inputs: []Direction{Right},
+			want:   `@Bl.`,
+		},
+		{
+			name:   "slide into a gate",
+			state:  `@~l`,
+			inputs: []Direction{Right},
+			want:   `.@l`,
+		},
+		{
+			name:   "carried into a gate",
+			state:  `@>l`,
+			inputs: []Direction{Right},
+			want:   `.@l`,
+		},
+	}
+
+	testCases(t, tt)
+}
@@ -0,0 +1,55 @@
+package game
+
+import (
+	"log/slog"
+	"slimesolver/game/math"
+)
+
+func (g *Game) IsIce(x, y int) bool {
+	return g.GetTokenAt(x, y) == IceToken
+}
+
+// stepOf returns the direction actor moves in this step, or false if it stands still.
+// Every slime moves in the first step of a move, after that only the actors sliding on ice
+// or carried by conveyors do.
+func (g *Game) stepOf(actor Actor, dir Direction) (Direction, bool) {
+	if g.moving == nil {
+		return dir, true
+	}
+	step, ok := g.moving[actor]
+	return step, ok
+}
+
+// slide resolves more steps in dir for as long as actors that moved in the last step stand on ice.
+// Every step is a graph of its own so sliding actors push, block and combine like they do
+// in the first step, while everything else stands still. Doors opened earlier in the move
+// stay open until it ends.
+func (g *Game) slide(dir Direction, from map[Actor]math.Vector2, trace *Trace) {
+	last := from
+	for steps := 0; ; steps++ {
+		sliding := make(map[Actor]Direction)
+		for _, actor := range g.actors {
+			pos := actor.GetPosition()
+			if !pos.Equals(last[actor]) && g.IsIce(pos.X, pos.Y) {
+				sliding[actor] = dir
+			}
+		}
+		if len(sliding) == 0 {
+			break
+		}
+		// an actor can't slide further than across the board
+		if steps > g.Width()+g.Height() {
+			panic("too many slides")
+		}
+
+		if g.logEnabled(slog.LevelDebug) {
+			g.logAttrs(slog.LevelDebug, "slide", slog.Int("turn", g.turn), slog.Int("actors", len(sliding)))
+		}
+		// slimes that combined don't block the ones sliding after them
+		g.removeKilled()
+		last = g.positions()
+		g.moving = sliding
+		g.resolve(dir, trace)
+	}
+	g.moving = nil
+}
@@ -0,0 +1,90 @@
+package game
+
+import "testing"
+
+func TestIce(t *testing.T) {
+	tt := []testCase{
+		{
+			name:   "slide to a wall",
+			state:  `@~~~#`,
+			inputs: []Direction{Right},
+			want:   `.~~@#`,
+		},
+		{
+			name:   "slide to the edge",
+			state:  `@~~`,
+			inputs: []Direction{Right},
+			want:   `.~@`,
+		},
+		{
+			name:   "stop on floor",
+			state:  `@~~..`,
+			inputs: []Direction{Right},
+			want:   `.~~@.`,
+		},
+		{
+			name:   "stop at a slime",
+			state:  `@~~~@#`,
+			inputs: []Direction{Right},
+			want:   `.~~@@#`,
+		},
+		{
+			name:   "slide together",
+			state:  `@~@~~#`,
+			inputs: []Direction{Right},
+			want:   `.~@~@#`,
+		},
+		{
+			name:   "push a box onto ice",
+			state:  `@B~~.`,
+			inputs: []Direction{Right},
+			want:   `.@~~B`,
+		},
+		{
+			name:   "slide into a box",
+			state:  `@~B~~#`,
+			inputs: []Direction{Right},
+			want:   `.~@~B#`,
+		},
+		{
+			name:   "small slimes combine",
+			state:  `o~~o#`,
+			inputs: []Direction{Right},
+			want:   `.~~@#`,
+		},
+		{
+			name:   "small slimes are stopped by boxes",
+			state:  `o~~B#`,
+			inputs: []Direction{Right},
+			want:   `.~oB#`,
+		},
+		{
+			name:   "slide into a pit",
+			state:  `@~~O.`,
+			inputs: []Direction{Right},
+			want:   `.~~O.`,
+		},
+		{
+			name: "doors stay open while sliding",
+			state: `@x...
+					@~~D.`,
+			inputs: []Direction{Right},
+			want: `.@...
+			       .~~@.`,
+		},
+		{
+			name: "slide up",
+			state: `.
+					~
+					~
+					@`,
+			inputs: []Direction{Up},
+			want: `@
+			       ~
+			       ~
+			       .`,
+		},
+	}
+
+	testCases(t, tt)
+}
@@ -0,0 +1,121 @@
+package game
+
+import "slimesolver/game/math"
+
+// Key is picked up by the first slime that ends a move on it.
+type Key struct {
+	PositionComponent
+}
+
+func NewKey(x, y int) *Key {
+	return &Key{
+		PositionComponent: PositionComponent{x, y},
+	}
+}
+
+func (k *Key) Token() Token {
+	return KeyToken
+}
+
+func (k *Key) String() string {
+	return string(k.Token())
+}
+
+func (k *Key) Transform(g *Game, dir Direction, affectingStates AffectingStates) (*StateChange, Actor) {
+	return nil, nil
+}
+
+func (k *Key) Apply(g *Game, change StateChange) {
+}
+
+// Tick gives the key to a slime standing on it. Picking it up once the move is over
+// means a slime that was stopped on the way never takes it.
+func (k *Key) Tick(g *Game) {
+	for _, actor := range g.GetActors(k.GetPosition()) {
+		if s, ok := actor.(*Slime); ok {
+			s.keys++
+			g.Kill(k)
+			return
+		}
+	}
+}
+
+func (k *Key) Solid() bool {
+	return false
+}
+
+func (k *Key) Damage(g *Game) {
+
+}
+
+func (k *Key) Clone() Actor {
+	c := *k
+	return &c
+}
+
+// LockedDoor blocks everything until a slime carrying a key moves into it.
+// The key is used up and the door is gone for good, the slime walks through in the same move.
+type LockedDoor struct {
+	PositionComponent
+	unlocked bool
+}
+
+func NewLockedDoor(x, y int) *LockedDoor {
+	return &LockedDoor{
+		PositionComponent: PositionComponent{x, y},
+	}
+}
+
+func (d *LockedDoor) Token() Token {
+	return LockedDoorToken
+}
+
+func (d *LockedDoor) String() string {
+	return string(d.Token())
+}
+
+func (d *LockedDoor) Transform(g *Game, dir Direction, affectingStates AffectingStates) (*StateChange, Actor) {
+	// a slime with a key unlocks the door before it moves onto it,
+	// parents move after children so the slime becomes our parent
+	for actor := range affectingStates.OnToStates {
+		if s, ok := actor.(*Slime); ok && s.keys > 0 {
+			return &StateChange{
+				Move:    math.NegVec,
+				Message: "unlock",
+				Updates: []Actor{s},
+			}, s
+		}
+	}
+	return nil, nil
+}
+
+// Apply takes the key from the slime that is unlocking the door.
+func (d *LockedDoor) Apply(g *Game, change StateChange) {
+	if change.Message != "unlock" || d.unlocked {
+		return
+	}
+	for _, actor := range change.Updates {
+		if s, ok := actor.(*Slime); ok && s.keys > 0 {
+			s.keys--
+			d.unlocked = true
+			g.Kill(d)
+			return
+		}
+	}
+}
+
+func (d *LockedDoor) Tick(g *Game) {
+}
+
+func (d *LockedDoor) Solid() bool {
+	return !d.unlocked
+}
+
+func (d *LockedDoor) Damage(g *Game) {
+
+}
+
+func (d *LockedDoor) Clone() Actor {
+	c := *d
+	return &c
+}
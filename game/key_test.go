@@ -0,0 +1,101 @@
+package game
+
+import (
+	"slimesolver/game/math"
+	"testing"
+)
+
+func TestLockedDoor(t *testing.T) {
+	tt := []testCase{
+		{
+			name:   "pick up a key",
+			state:  `@k.`,
+			inputs: []Direction{Right},
+			want:   `.@.`,
+		},
+		{
+			name:   "locked without a key",
+			state:  `@L.`,
+			inputs: []Direction{Right},
+			want:   `@L.`,
+		},
+		{
+			name:   "unlock with a key",
+			state:  `@kL.`,
+			inputs: []Direction{Right, Right},
+			want:   `..@.`,
+		},
+		{
+			name:   "a key opens one door",
+			state:  `@kLL`,
+			inputs: []Direction{Right, Right, Right},
+			want:   `..@L`,
+		},
+		{
+			name:   "follow through the unlocked door",
+			state:  `@@kL.`,
+			inputs: []Direction{Right, Right},
+			want:   `..@@.`,
+		},
+		{
+			name:   "boxes don't unlock",
+			state:  `@kBL`,
+			inputs: []Direction{Right, Right},
+			want:   `.@BL`,
+		},
+	}
+
+	testCases(t, tt)
+}
+
+func TestKeys(t *testing.T) {
+	tt := []struct {
+		name   string
+		state  string
+		inputs []Direction
+		// keys carried by the slime on each tile
+		want map[math.Vector2]int
+	}{
+		{
+			name:   "boxes leave keys behind",
+			state:  `@Bk.`,
+			inputs: []Direction{Right, Right},
+			want:   map[math.Vector2]int{{X: 2, Y: 0}: 1},
+		},
+		{
+			name:   "the half that stays keeps the key",
+			state:  `@k^`,
+			inputs: []Direction{Right, Right},
+			want:   map[math.Vector2]int{{X: 2, Y: 0}: 1, {X: 1, Y: 0}: 0},
+		},
+		{
+			name:   "combining keeps the key",
+			state:  `ok.o#`,
+			inputs: []Direction{Right, Right, Right},
+			want:   map[math.Vector2]int{{X: 3, Y: 0}: 1},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGame(testLogger(t))
+			if err := g.Parse(tc.state); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, dir := range tc.inputs {
+				g.Move(dir)
+			}
+
+			slimes := g.GetActorsWithTokens([]Token{SlimeToken, SmallSlimeToken})
+			if len(slimes) != len(tc.want) {
+				t.Fatalf("expected %d slimes, got\n%s", len(tc.want), g.String())
+			}
+			for _, actor := range slimes {
+				keys, ok := tc.want[actor.GetPosition()]
+				if got := actor.(*Slime).Keys(); !ok || got != keys {
+					t.Fatalf("expected the slime at %v to carry %d keys, got %d\n%s", actor.GetPosition(), keys, got, g.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package game
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LevelTrace is more verbose than slog.LevelDebug, it logs every step of
+// resolving a move.
+const LevelTrace = slog.LevelDebug - 4
+
+// discardHandler drops every record, it is used when no logger is given so
+// logging calls never format anything.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+var discardLogger = slog.New(discardHandler{})
+
+// logEnabled should guard logging on hot paths so building the attributes
+// costs nothing when logging is off.
+func (g *Game) logEnabled(level slog.Level) bool {
+	return g.log.Enabled(context.Background(), level)
+}
+
+func (g *Game) logAttrs(level slog.Level, msg string, attrs ...slog.Attr) {
+	g.log.LogAttrs(context.Background(), level, msg, attrs...)
+}
+
+// actorID is the index of actor in the game, -1 once it has been removed.
+func (g *Game) actorID(actor Actor) int {
+	for i, a := range g.actors {
+		if a == actor {
+			return i
+		}
+	}
+	return -1
+}
+
+func (g *Game) actorAttr(actor Actor) slog.Attr {
+	return slog.Group("actor",
+		slog.Int("id", g.actorID(actor)),
+		slog.String("token", actor.String()),
+		slog.String("pos", actor.GetPosition().String()),
+	)
+}
@@ -0,0 +1,74 @@
+package game
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestLogging(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: LevelTrace}))
+
+	g := NewGame(logger)
+	err := g.Parse(`@BO`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	g.Move(Right)
+
+	records := make(map[string][]map[string]any)
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var record map[string]any
+		if err := dec.Decode(&record); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		msg := record["msg"].(string)
+		records[msg] = append(records[msg], record)
+	}
+
+	move := records["move"]
+	if len(move) != 1 || move[0]["level"] != "DEBUG" || move[0]["turn"] != 1.0 || move[0]["dir"] != "right" {
+		t.Fatalf("expected a single debug move record, got %v", move)
+	}
+
+	if len(records["resolve"]) == 0 || len(records["leaf"]) == 0 {
+		t.Fatalf("expected resolve and leaf records, got %v", records)
+	}
+
+	apply := records["apply"]
+	if len(apply) != 2 {
+		t.Fatalf("expected 2 apply records, got %v", apply)
+	}
+	actor := apply[0]["actor"].(map[string]any)
+	if actor["id"] != 1.0 || actor["token"] != "B" || apply[0]["wave"] != 0.0 {
+		t.Fatalf("expected the box to be applied first, got %v", apply[0])
+	}
+
+	kill := records["kill"]
+	if len(kill) != 1 || kill[0]["actor"].(map[string]any)["token"] != "B" {
+		t.Fatalf("expected the box to be killed by the pit, got %v", kill)
+	}
+}
+
+func TestNoLogger(t *testing.T) {
+	g := NewGame(nil)
+	if g.logEnabled(LevelTrace) || g.logEnabled(slog.LevelError) {
+		t.Fatalf("expected logging to be off without a logger")
+	}
+}
+
+func BenchmarkMove(b *testing.B) {
+	b.ReportAllocs()
+	g := NewGame(nil)
+	for i := 0; i < b.N; i++ {
+		err := g.Parse(`@.B.x#D`)
+		if err != nil {
+			b.Fatalf("unexpected error: %v", err)
+		}
+		g.Move(Right)
+		g.Move(Right)
+	}
+}
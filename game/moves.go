@@ -0,0 +1,47 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+// move letters used when writing replays and solutions
+var moveLetters = map[Direction]rune{
+	Up:    'u',
+	Down:  'd',
+	Left:  'l',
+	Right: 'r',
+}
+
+// ParseMoves parses a replay written as a string of u, d, l and r letters.
+// Letters are case insensitive and whitespace or commas between them are ignored.
+func ParseMoves(s string) ([]Direction, error) {
+	moves := make([]Direction, 0, len(s))
+	for _, c := range strings.ToLower(s) {
+		switch c {
+		case 'u':
+			moves = append(moves, Up)
+		case 'd':
+			moves = append(moves, Down)
+		case 'l':
+			moves = append(moves, Left)
+		case 'r':
+			moves = append(moves, Right)
+		case ' ', '\t', '\n', '\r', ',':
+		default:
+			return nil, fmt.Errorf("invalid move: %c", c)
+		}
+	}
+	return moves, nil
+}
+
+// FormatMoves writes a replay in the format read by ParseMoves.
+func FormatMoves(moves []Direction) string {
+	var sb strings.Builder
+	for _, dir := range moves {
+		if c, ok := moveLetters[dir]; ok {
+			sb.WriteRune(c)
+		}
+	}
+	return sb.String()
+}
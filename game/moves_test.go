@@ -0,0 +1,52 @@
+package game
+
+import "testing"
+
+func TestParseMoves(t *testing.T) {
+	tt := []struct {
+		name  string
+		moves string
+		want  []Direction
+		err   bool
+	}{
+		{
+			name:  "empty",
+			moves: ``,
+			want:  []Direction{},
+		},
+		{
+			name:  "all directions",
+			moves: `udlr`,
+			want:  []Direction{Up, Down, Left, Right},
+		},
+		{
+			name:  "upper case with separators",
+			moves: `U, D R`,
+			want:  []Direction{Up, Down, Right},
+		},
+		{
+			name:  "invalid letter",
+			moves: `uw`,
+			err:   true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			moves, err := ParseMoves(tc.moves)
+			if tc.err {
+				if err == nil {
+					t.Fatalf("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if FormatMoves(moves) != FormatMoves(tc.want) || len(moves) != len(tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, moves)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package game
+
+import "slimesolver/game/math"
+
+// ActorEvent describes what happened to one actor during a move.
+type ActorEvent struct {
+	// Token before the move and after it, they differ when a door toggles,
+	// a spike flips or a slime grows or splits
+	Token    Token
+	NewToken Token
+	From     math.Vector2
+	To       math.Vector2
+}
+
+// Report is everything that changed during a single move,
+// it is meant for clients that animate a turn.
+type Report struct {
+	Turn      int
+	Direction Direction
+	// Moved actors changed tile
+	Moved []ActorEvent
+	// Changed actors changed token
+	Changed []ActorEvent
+	// Killed actors were removed, To is where they were last
+	Killed []ActorEvent
+	// Spawned actors were added, From and To are where they appeared
+	Spawned []ActorEvent
+	Won     bool
+}
+
+// MoveReport is Move but it reports every actor that moved, changed, died or spawned.
+func (g *Game) MoveReport(dir Direction) Report {
+	before := make([]Actor, len(g.actors))
+	copy(before, g.actors)
+	positions := make([]math.Vector2, len(before))
+	tokens := make([]Token, len(before))
+	for i, actor := range before {
+		positions[i] = actor.GetPosition()
+		tokens[i] = actor.Token()
+	}
+
+	g.Move(dir)
+
+	alive := make(map[Actor]bool, len(g.actors))
+	for _, actor := range g.actors {
+		alive[actor] = true
+	}
+
+	report := Report{
+		Turn:      g.turn,
+		Direction: dir,
+		Won:       g.Won(),
+	}
+	existed := make(map[Actor]bool, len(before))
+	for i, actor := range before {
+		existed[actor] = true
+		event := ActorEvent{
+			Token:    tokens[i],
+			NewToken: actor.Token(),
+			From:     positions[i],
+			To:       actor.GetPosition(),
+		}
+
+		if !alive[actor] {
+			report.Killed = append(report.Killed, event)
+			continue
+		}
+		if !event.From.Equals(event.To) {
+			report.Moved = append(report.Moved, event)
+		}
+		if event.Token != event.NewToken {
+			report.Changed = append(report.Changed, event)
+		}
+	}
+
+	for _, actor := range g.actors {
+		if existed[actor] {
+			continue
+		}
+		pos := actor.GetPosition()
+		report.Spawned = append(report.Spawned, ActorEvent{
+			Token:    actor.Token(),
+			NewToken: actor.Token(),
+			From:     pos,
+			To:       pos,
+		})
+	}
+	return report
+}
@@ -0,0 +1,51 @@
+package game
+
+import (
+	"slimesolver/game/math"
+	"testing"
+)
+
+func TestMoveReport(t *testing.T) {
+	g := NewGame(testLogger(t))
+	err := g.Parse(`@xD.-O`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	report := g.MoveReport(Right)
+	if report.Turn != 1 || report.Direction != Right || report.Won {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if len(report.Moved) != 1 || report.Moved[0].Token != SlimeToken ||
+		!report.Moved[0].From.Equals(math.Vector2{X: 0, Y: 0}) || !report.Moved[0].To.Equals(math.Vector2{X: 1, Y: 0}) {
+		t.Fatalf("expected the slime to move onto the switch, got %+v", report.Moved)
+	}
+
+	// the door opens and the spike comes up
+	if len(report.Changed) != 2 {
+		t.Fatalf("expected 2 changed actors, got %+v", report.Changed)
+	}
+	if report.Changed[0].Token != ClosedDoorToken || report.Changed[0].NewToken != OpenDoorToken {
+		t.Fatalf("expected the door to open, got %+v", report.Changed[0])
+	}
+	if report.Changed[1].Token != SpikeDownToken || report.Changed[1].NewToken != SpikeUpToken {
+		t.Fatalf("expected the spike to come up, got %+v", report.Changed[1])
+	}
+}
+
+func TestMoveReportKillAndSpawn(t *testing.T) {
+	g := NewGame(testLogger(t))
+	err := g.Parse(`@-.
+					@O.`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	report := g.MoveReport(Right)
+	if len(report.Killed) != 1 || report.Killed[0].Token != SlimeToken || !report.Killed[0].To.Equals(math.Vector2{X: 1, Y: 1}) {
+		t.Fatalf("expected the slime to die in the pit, got %+v", report.Killed)
+	}
+	if len(report.Spawned) != 1 || report.Spawned[0].Token != SmallSlimeToken || !report.Spawned[0].To.Equals(math.Vector2{X: 0, Y: 0}) {
+		t.Fatalf("expected the slime to split onto its last tile, got %+v", report.Spawned)
+	}
+}
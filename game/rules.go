@@ -0,0 +1,128 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// RulePrefix starts the lines above the board of a level that change its rules,
+// one rule per line written as "; name = value", for example "; doors-crush = off".
+const RulePrefix = ";"
+
+// Rules are the settings of mechanics that differ between levels.
+type Rules struct {
+	// PushStrength is how many crates a big slime pushes on its own. Slimes moving in a line
+	// behind each other add up their strength, a slime is as strong as the small slimes it is made of.
+	PushStrength int
+	// SlimeSize is the largest slime slimes combine into, up to MaxSlimeSize.
+	// Slimes whose sizes add up to more block each other.
+	SlimeSize int
+	// DoorsCrush kills what a door closes on, otherwise the door stays open until its tile is free
+	DoorsCrush bool
+	// SmallSlimesPress lets small slimes press switches, otherwise they only press one while growing
+	SmallSlimesPress bool
+	// PitsKill kills slimes that end a move on a pit, otherwise slimes walk over pits.
+	// Crates fall into pits either way.
+	PitsKill bool
+	// SpikesAlternate raises and lowers every spike after each move,
+	// otherwise raised spikes stay up and lowered spikes stay down
+	SpikesAlternate bool
+}
+
+// DefaultRules are the rules levels are played with unless they say otherwise.
+func DefaultRules() Rules {
+	return Rules{
+		PushStrength:    1,
+		SlimeSize:       2,
+		DoorsCrush:      true,
+		PitsKill:        true,
+		SpikesAlternate: true,
+	}
+}
+
+// Rules returns the rules the game is played with.
+func (g *Game) Rules() Rules {
+	return g.rules
+}
+
+// SetRules changes the rules the game is played with.
+// Parse replaces them with the rules of the level it reads.
+func (g *Game) SetRules(rules Rules) {
+	g.rules = rules
+}
+
+// ParseRules reads the rule lines at the top of a level. It returns the default rules
+// changed by them and the rest of the level.
+func ParseRules(level string) (Rules, string, error) {
+	rules := DefaultRules()
+	lines := strings.Split(strings.ReplaceAll(level, "\r\n", "\n"), "\n")
+	n := 0
+	for ; n < len(lines); n++ {
+		line := strings.TrimSpace(lines[n])
+		if !strings.HasPrefix(line, RulePrefix) {
+			break
+		}
+		if err := rules.set(strings.TrimPrefix(line, RulePrefix)); err != nil {
+			return rules, "", err
+		}
+	}
+	return rules, strings.Join(lines[n:], "\n"), nil
+}
+
+// set changes the rule written as "name = value".
+func (r *Rules) set(line string) error {
+	name, value, ok := strings.Cut(line, "=")
+	if !ok {
+		return fmt.Errorf("invalid rule: %s", strings.TrimSpace(line))
+	}
+	name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+
+	if number, ok := r.numbers()[name]; ok {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < number.min || n > number.max {
+			return fmt.Errorf("invalid value for %s: %s", name, value)
+		}
+		*number.field = n
+		return nil
+	}
+
+	flag, ok := r.flags()[name]
+	if !ok {
+		return fmt.Errorf("unknown rule: %s", name)
+	}
+	switch value {
+	case "on":
+		*flag = true
+	case "off":
+		*flag = false
+	default:
+		return fmt.Errorf("invalid value for %s: %s, want on or off", name, value)
+	}
+	return nil
+}
+
+// numberRule is a rule set to a number between min and max.
+type numberRule struct {
+	field    *int
+	min, max int
+}
+
+// numbers maps the names of the rules that are numbers to their fields.
+func (r *Rules) numbers() map[string]numberRule {
+	return map[string]numberRule{
+		"push-strength": {&r.PushStrength, 1, math.MaxInt},
+		"slime-size":    {&r.SlimeSize, 1, MaxSlimeSize},
+	}
+}
+
+// flags maps the names of the rules that are either on or off to their fields.
+func (r *Rules) flags() map[string]*bool {
+	return map[string]*bool{
+		"doors-crush":        &r.DoorsCrush,
+		"small-slimes-press": &r.SmallSlimesPress,
+		"pits-kill":          &r.PitsKill,
+		"spikes-alternate":   &r.SpikesAlternate,
+	}
+}
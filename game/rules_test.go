@@ -0,0 +1,208 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRules(t *testing.T) {
+	rules := func(change func(r *Rules)) *Rules {
+		r := DefaultRules()
+		change(&r)
+		return &r
+	}
+	doorsBlock := rules(func(r *Rules) { r.DoorsCrush = false })
+	smallPress := rules(func(r *Rules) { r.SmallSlimesPress = true })
+	safePits := rules(func(r *Rules) { r.PitsKill = false })
+	stillSpikes := rules(func(r *Rules) { r.SpikesAlternate = false })
+
+	tt := []testCase{
+		{
+			name: "door crushes",
+			state: `@x.#@D#
+					...#...`,
+			inputs: []Direction{Right, Right, Down},
+			want: `.x.#.D#
+				   ..@#...`,
+		},
+		{
+			name: "door blocks until its tile is free",
+			state: `@x.#@D#
+					...#...`,
+			inputs: []Direction{Right, Right, Down},
+			want: `.x.#.D#
+				   ..@#.@.`,
+			rules: doorsBlock,
+		},
+		{
+			name: "door stays open over a slime",
+			state: `@x.#@D#
+					...#...`,
+			inputs: []Direction{Right, Right},
+			want: `.x@#.@#
+				   ...#...`,
+			rules: doorsBlock,
+		},
+		{
+			name:   "small slime doesn't press a switch",
+			state:  `ox.D`,
+			inputs: []Direction{Right},
+			want:   `.o.D`,
+		},
+		{
+			name:   "small slime presses a switch",
+			state:  `ox.D`,
+			inputs: []Direction{Right},
+			want:   `.o._`,
+			rules:  smallPress,
+		},
+		{
+			name:   "pit kills a slime",
+			state:  `@O.`,
+			inputs: []Direction{Right, Right},
+			want:   `.O.`,
+		},
+		{
+			name:   "slime walks over a pit",
+			state:  `@O.`,
+			inputs: []Direction{Right, Right},
+			want:   `.O@`,
+			rules:  safePits,
+		},
+		{
+			name:   "box still falls into a pit",
+			state:  `@BO.`,
+			inputs: []Direction{Right, Right},
+			want:   `..@.`,
+			rules:  safePits,
+		},
+		{
+			name:   "raised spike goes down",
+			state:  `@^.`,
+			inputs: []Direction{Right},
+			want:   `.@.`,
+		},
+		{
+			name:   "raised spike stays up",
+			state:  `@^.`,
+			inputs: []Direction{Right},
+			want:   `oo.`,
+			rules:  stillSpikes,
+		},
+		{
+			name:   "lowered spike stays down",
+			state:  `@.-`,
+			inputs: []Direction{Right, Right, Down},
+			want:   `..@`,
+			rules:  stillSpikes,
+		},
+		{
+			name:   "rules from the level",
+			state:  "; small-slimes-press = on\n#ox@D*#",
+			inputs: []Direction{Right, Right},
+			want:   `#.xoD@#`,
+		},
+		{
+			name:   "default rules without rule lines",
+			state:  `#ox@D*#`,
+			inputs: []Direction{Right, Right},
+			want:   `#.o@D*#`,
+		},
+	}
+
+	testCases(t, tt)
+}
+
+func TestParseRules(t *testing.T) {
+	tt := []struct {
+		name  string
+		level string
+		want  Rules
+		board string
+		err   string
+	}{
+		{
+			name:  "no rule lines",
+			level: "@.*",
+			want:  DefaultRules(),
+			board: "@.*",
+		},
+		{
+			name:  "every rule",
+			level: "; push-strength = 2\n; slime-size = 3\n;doors-crush=off\n ; small-slimes-press = on\n; pits-kill = off\n; spikes-alternate = off\r\n@.*",
+			want: Rules{
+				PushStrength:     2,
+				SlimeSize:        3,
+				SmallSlimesPress: true,
+			},
+			board: "@.*",
+		},
+		{
+			name:  "unknown rule",
+			level: "; doors = off\n@.*",
+			err:   "unknown rule: doors",
+		},
+		{
+			name:  "missing value",
+			level: "; pits-kill\n@.*",
+			err:   "invalid rule: pits-kill",
+		},
+		{
+			name:  "invalid flag",
+			level: "; pits-kill = yes\n@.*",
+			err:   "invalid value for pits-kill: yes, want on or off",
+		},
+		{
+			name:  "invalid strength",
+			level: "; push-strength = 0\n@.*",
+			err:   "invalid value for push-strength: 0",
+		},
+		{
+			name:  "slime size without a token",
+			level: "; slime-size = 5\n@.*",
+			err:   "invalid value for slime-size: 5",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			rules, board, err := ParseRules(tc.level)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("expected error %q, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if rules != tc.want {
+				t.Fatalf("expected %+v, got %+v", tc.want, rules)
+			}
+			if board != tc.board {
+				t.Fatalf("expected board %q, got %q", tc.board, board)
+			}
+		})
+	}
+}
+
+func TestParseSetsRules(t *testing.T) {
+	g := NewGame(nil)
+	if err := g.Parse("; pits-kill = off\n@O*"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if g.Rules().PitsKill {
+		t.Fatalf("expected pits not to kill")
+	}
+	if got := strings.TrimSpace(g.String()); got != "@O*" {
+		t.Fatalf("expected the board without rule lines, got %q", got)
+	}
+
+	// a level without rule lines is played with the default rules
+	if err := g.Parse("@O*"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if g.Rules() != DefaultRules() {
+		t.Fatalf("expected the default rules, got %+v", g.Rules())
+	}
+}
@@ -1,34 +1,84 @@
 package game
 
 import (
-	"fmt"
+	"log/slog"
 	"slimesolver/game/math"
 )
 
+// slimeTokens are the tokens of slimes by size, starting with a small slime of size 1
+var slimeTokens = [...]Token{SmallSlimeToken, SlimeToken, LargeSlimeToken, HugeSlimeToken}
+
+// MaxSlimeSize is the largest size a slime has a token for.
+const MaxSlimeSize = len(slimeTokens)
+
 type Slime struct {
 	PositionComponent
-	small        bool
+	// size is how many small slimes the slime is made of, from 1 up to Rules.SlimeSize
+	size         int
 	lastPosition math.Vector2
+	// keys picked up and not used yet
+	keys int
 }
 
+// NewSlime creates a small slime or a slime of size 2.
 func NewSlime(x, y int, small bool) *Slime {
+	if small {
+		return NewSizedSlime(x, y, 1)
+	}
+	return NewSizedSlime(x, y, 2)
+}
+
+// NewSizedSlime creates a slime made of size small slimes.
+func NewSizedSlime(x, y, size int) *Slime {
 	return &Slime{
 		PositionComponent: PositionComponent{x, y},
-		small:             small,
+		size:              size,
 	}
 }
 
 func (s *Slime) Token() Token {
-	if s.small {
-		return SmallSlimeToken
+	return slimeTokens[s.size-1]
+}
+
+// Size returns how many small slimes the slime is made of.
+func (s *Slime) Size() int {
+	return s.size
+}
+
+// Keys returns how many keys the slime carries.
+func (s *Slime) Keys() int {
+	return s.keys
+}
+
+// SlimeTokens returns the tokens of slimes of every size, smallest first.
+func SlimeTokens() []Token {
+	return append([]Token(nil), slimeTokens[:]...)
+}
+
+// IsSlimeToken reports whether token is a slime of any size.
+func IsSlimeToken(token Token) bool {
+	return SlimeSize(token) > 0
+}
+
+// SlimeSize returns the size of the slime written as token, or 0 if it isn't a slime.
+func SlimeSize(token Token) int {
+	for i, t := range slimeTokens {
+		if t == token {
+			return i + 1
+		}
 	}
-	return SlimeToken
+	return 0
 }
 
 func (s *Slime) String() string {
 	return string(s.Token())
 }
 
+func isSlime(actor Actor) bool {
+	_, ok := actor.(*Slime)
+	return ok
+}
+
 func possibleBlockerStates(affectingStates AffectingStates) map[Actor]StateChange {
 	possibleBlockers := affectingStates.GoingToStates
 	for actor, change := range affectingStates.WatchingStates {
@@ -39,6 +89,7 @@ func possibleBlockerStates(affectingStates AffectingStates) map[Actor]StateChang
 
 func (s *Slime) Transform(g *Game, dir Direction, affectingStates AffectingStates) (*StateChange, Actor) {
 	pos := s.GetPosition()
+	dir, moving := g.stepOf(s, dir)
 	move := moveVector(pos, dir)
 	nextChange := &StateChange{
 		Move: move,
@@ -46,19 +97,32 @@ func (s *Slime) Transform(g *Game, dir Direction, affectingStates AffectingState
 	var parent Actor
 
 	// things moving to where we are need to wait for us to move
-	// so they become our parent
-	for actor, _ := range affectingStates.OnToStates {
-		parent = actor
+	// so they become our parent, the first of them so the parent doesn't change between steps
+	for _, actor := range g.actors {
+		if _, ok := affectingStates.OnToStates[actor]; ok {
+			parent = actor
+			break
+		}
 	}
 
-	// can't move if we're going to hit a wall
-	if g.IsWallOrEdge(move.X, move.Y) {
+	// can't move if we're going to hit a wall or a gate the wrong way, or nothing moves us this step
+	if g.IsBlocked(move, dir) || !moving {
 		nextChange.Move = pos
 	}
 
+	// slimes bigger than a small one push together with the slimes moving onto their tile
+	// from behind and add their strength to ours. Slimes behind a small one combine with it instead.
+	nextChange.Push = s.strength(g)
+	for actor, change := range affectingStates.OnToStates {
+		if s.size > 1 && isSlime(actor) && change.From.Equals(moveVector(pos, opposite(dir))) {
+			nextChange.Push += change.Push
+		}
+	}
+
 	// doors that aren't opening block our movement
-	if len(affectingStates.GoingToStates) > 0 {
-		fmt.Println("going to states: ", affectingStates.GoingToStates)
+	if len(affectingStates.GoingToStates) > 0 && g.logEnabled(LevelTrace) {
+		g.logAttrs(LevelTrace, "going to states", slog.Int("turn", g.turn), g.actorAttr(s),
+			slog.Any("states", affectingStates.GoingToStates))
 	}
 	possibleBlockers := possibleBlockerStates(affectingStates) // includes going to and watched states
 	for actor, change := range possibleBlockers {
@@ -71,44 +135,145 @@ func (s *Slime) Transform(g *Game, dir Direction, affectingStates AffectingState
 		}
 	}
 
-	if s.small {
-		// small slime is blocked by boxes and normal slimes
+	if s.size < g.rules.SlimeSize {
+		// a slime that can still grow is blocked by crates the line it leads can't push
 		for actor, _ := range possibleBlockers {
-			token := actor.Token()
-			switch token {
-			case BoxToken, SlimeToken:
+			if a, ok := actor.(*Box); ok {
+				load, free := g.crates(move, dir)
+				if !a.GetPosition().Equals(move) || !free || nextChange.Push < load {
+					nextChange.Move = pos
+					nextChange.Watching = append(nextChange.Watching, actor) // we need to keep track of this actor in future transforms
+				}
+			}
+		}
+	}
+
+	s.meet(g, affectingStates, nextChange)
+
+	return nextChange, parent
+}
+
+// meet combines the slime with the slimes ending up on the same tile this step.
+// The slime already standing on the tile, or else the first of them in the actors, stays
+// and grows: the others combine into it in the order of the actors as long as they fit,
+// the ones that don't fit are blocked. Two slimes moving into each other head on meet
+// on the tile of the first of them in the actors, so it stays and the other moves onto it.
+func (s *Slime) meet(g *Game, affectingStates AffectingStates, nextChange *StateChange) {
+	pos := s.GetPosition()
+	slimes := make(map[Actor]StateChange)
+	for _, states := range []map[Actor]StateChange{affectingStates.OnToStates, affectingStates.GoingToStates, affectingStates.WatchingStates} {
+		for actor, change := range states {
+			if isSlime(actor) {
+				slimes[actor] = change
+			}
+		}
+	}
+
+	// a slime moving onto us from where we're going meets us head on
+	if to := nextChange.Move; !to.Equals(pos) {
+		for actor, change := range slimes {
+			if change.From.Equals(to) && change.Move.Equals(pos) && g.before(s, actor) {
 				nextChange.Move = pos
+				nextChange.Message = "stay"
 				nextChange.Watching = append(nextChange.Watching, actor) // we need to keep track of this actor in future transforms
 			}
 		}
+	}
 
-		// if this slime is not going to move
-		// and another small slime will move into it this turn
-		// then we need to grow
-		if nextChange.Move.Equals(pos) {
-			for actor, _ := range affectingStates.OnToStates {
-				if actor.Token() == SmallSlimeToken {
-					nextChange.Message = "grow"
-				}
+	// the slimes ending up where we do, in the order of the actors, and the one they combine into
+	to := nextChange.Move
+	var meeting []*Slime
+	var host *Slime
+	// whether the host stands there for a reason of its own, not held back by a slime
+	wall := false
+	for _, actor := range g.actors {
+		other, ok := actor.(*Slime)
+		if !ok {
+			continue
+		}
+		if other == s {
+			meeting = append(meeting, s)
+			if to.Equals(pos) {
+				host = s
 			}
+			continue
 		}
+		if change, ok := slimes[actor]; ok && change.Move.Equals(to) {
+			meeting = append(meeting, other)
+			if change.From.Equals(to) {
+				host = other
+				wall = change.Message != "stay"
+			}
+		}
+	}
+	if len(meeting) < 2 {
+		return
+	}
+	if host == nil {
+		host = meeting[0]
+	}
 
-		// if we're moving into another small slime, we need to combine
-		for actor, change := range affectingStates.GoingToStates {
-			if actor.Token() == SmallSlimeToken && change.Message == "grow" {
-				nextChange.Message = "combine"
+	size := host.size
+	for _, other := range meeting {
+		if other == host {
+			continue
+		}
+		fits := size+other.size <= g.rules.SlimeSize
+		if fits {
+			size += other.size
+		}
+		switch {
+		case host == s && fits:
+			nextChange.Message = "grow"
+			nextChange.Updates = append(nextChange.Updates, other)
+		case other == s && fits:
+			nextChange.Message = "combine"
+		case other == s:
+			// blocked, so we meet the slimes moving onto us instead. Only a slime that can
+			// still grow bumps into a slime standing there like into a wall, see Apply
+			nextChange.Move = pos
+			if !wall || s.size >= g.rules.SlimeSize {
+				nextChange.Message = "stay"
 			}
+			for _, watched := range meeting {
+				if watched != s {
+					nextChange.Watching = append(nextChange.Watching, watched) // we need to keep track of these actors in future transforms
+				}
+			}
+			s.meet(g, affectingStates, nextChange)
+			return
 		}
 	}
+}
 
-	return nextChange, parent
+// fits reports whether the slime and other combine into a slime no bigger than the rules allow.
+func (s *Slime) fits(g *Game, other *Slime) bool {
+	return s.size+other.size <= g.rules.SlimeSize
+}
+
+// strength is how hard the slime pushes, every small slime it is made of adds the same.
+func (s *Slime) strength(g *Game) int {
+	return s.size * g.rules.PushStrength
 }
 
 func (s *Slime) Apply(g *Game, change StateChange) {
-	if change.Message == "grow" {
-		s.small = false
-	} else if change.Message == "combine" {
+	switch change.Message {
+	case "combine":
+		// the slime we combine into takes our size and keys
 		g.Kill(s)
+		return
+	case "stay":
+		// held back by another slime, we didn't bump into anything so
+		// we still split off towards where we came from
+		return
+	case "grow":
+		for _, actor := range change.Updates {
+			if other, ok := actor.(*Slime); ok {
+				s.size += other.size
+				s.keys += other.keys
+				other.keys = 0
+			}
+		}
 	}
 
 	// check if we can move
@@ -124,7 +289,7 @@ func (s *Slime) Apply(g *Game, change StateChange) {
 
 func (s *Slime) Tick(g *Game) {
 	// die if we're on a pit
-	if g.IsPit(s.X, s.Y) {
+	if g.rules.PitsKill && g.IsPit(s.X, s.Y) {
 		g.Kill(s)
 		return
 	}
@@ -134,18 +299,26 @@ func (s *Slime) Solid() bool {
 	return true
 }
 
+// Damage splits a slime in two halves and kills a small one. The half that stays
+// where it is keeps the bigger half of an odd size and the keys, the slime split off carries none.
 func (s *Slime) Damage(g *Game) {
-	if s.small {
+	if s.size == 1 {
 		g.Kill(s)
 		return
 	}
 
-	s.small = true
+	split := s.size / 2
+	s.size -= split
 
+	pos := s.GetPosition()
 	spawnLocations := s.getSpawnLocations()
 	for _, loc := range spawnLocations {
+		// a slime that bumped into something last turn came from its own tile
+		if loc.Equals(pos) {
+			continue
+		}
 		if canMoveTo(g, loc, s) {
-			g.AddActor(NewSlime(loc.X, loc.Y, true))
+			g.AddActor(NewSizedSlime(loc.X, loc.Y, split))
 			return
 		}
 	}
@@ -161,3 +334,8 @@ func (s *Slime) getSpawnLocations() []math.Vector2 {
 		moveVector(pos, Right),
 	}
 }
+
+func (s *Slime) Clone() Actor {
+	c := *s
+	return &c
+}
@@ -1,6 +1,10 @@
 package game
 
-import "testing"
+import (
+	"fmt"
+	"slimesolver/game/math"
+	"testing"
+)
 
 func TestBasicMovement(t *testing.T) {
 	tt := []testCase{
@@ -228,3 +232,286 @@ func TestSmallSlime(t *testing.T) {
 
 	testCases(t, tt)
 }
+
+func TestSlimeSizes(t *testing.T) {
+	tt := []testCase{
+		{
+			name:   "huge slime splits into two slimes",
+			state:  "; slime-size = 4\n%-",
+			inputs: []Direction{Right},
+			want:   `@@`,
+		},
+		{
+			name:   "large slime keeps the bigger half",
+			state:  "; slime-size = 3\n&-",
+			inputs: []Direction{Right},
+			want:   `o@`,
+		},
+		{
+			name:   "small slime combines into a slime",
+			state:  "; slime-size = 3\no@#",
+			inputs: []Direction{Right},
+			want:   `.&#`,
+		},
+		{
+			name:   "small slime can't combine into a slime by default",
+			state:  `o@#`,
+			inputs: []Direction{Right},
+			want:   `o@#`,
+		},
+		{
+			name:   "slimes too big to combine block each other",
+			state:  "; slime-size = 3\n@@#",
+			inputs: []Direction{Right},
+			want:   `@@#`,
+		},
+		{
+			name:   "two slimes combine into a huge slime",
+			state:  "; slime-size = 4\n@@#",
+			inputs: []Direction{Right},
+			want:   `.%#`,
+		},
+		{
+			name:   "huge slime pushes a heavy crate",
+			state:  "; slime-size = 4\n%H.",
+			inputs: []Direction{Right},
+			want:   `.%H`,
+		},
+		{
+			name:   "large slime can't push a heavy crate",
+			state:  "; slime-size = 3\n&H.",
+			inputs: []Direction{Right},
+			want:   `&H.`,
+		},
+		{
+			name:   "large slime presses a switch",
+			state:  "; slime-size = 3\n&x.D",
+			inputs: []Direction{Right},
+			want:   `.&._`,
+		},
+	}
+
+	testCases(t, tt)
+}
+
+func TestSlimeSizeParse(t *testing.T) {
+	g := NewGame(nil)
+	if err := g.Parse("&.*"); err == nil {
+		t.Fatalf("expected a large slime to be bigger than the default slime size")
+	}
+	if err := g.Parse("; slime-size = 3\n&.*"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s := g.Actors()[0].(*Slime)
+	if s.Size() != 3 || s.Token() != LargeSlimeToken {
+		t.Fatalf("expected a large slime, got size %d %c", s.Size(), s.Token())
+	}
+}
+
+func TestMeeting(t *testing.T) {
+	tt := []testCase{
+		{
+			name:   "small slimes carried onto the same tile combine",
+			state:  "o.o\n>.<",
+			inputs: []Direction{Down},
+			want:   "...\n>@<",
+		},
+		{
+			name:   "slimes carried onto the same tile combine up to the slime size",
+			state:  "; slime-size = 3\n@.o\n>.<",
+			inputs: []Direction{Down},
+			want:   "...\n>&<",
+		},
+		{
+			name: "three small slimes carried onto the same tile combine in order until it is full",
+			state: `..o..
+					.ovo.
+					.>.<.`,
+			inputs: []Direction{Down},
+			want: `.....
+				   ..v..
+				   .>@o.`,
+		},
+		{
+			name: "slimes arriving on a slime standing still combine in order until it is full",
+			state: `; slime-size = 3
+					..o..
+					.ovo.
+					.>o<.
+					..#..`,
+			inputs: []Direction{Down},
+			want: `.....
+				   ..v..
+				   .>&o.
+				   ..#..`,
+		},
+		{
+			name: "three small slimes combine",
+			state: `; slime-size = 3
+					..o..
+					.ovo.
+					.>.<.`,
+			inputs: []Direction{Down},
+			want: `.....
+				   ..v..
+				   .>&<.`,
+		},
+		{
+			name: "slimes arrive on a slime standing still",
+			state: `; slime-size = 4
+					..o..
+					.ovo.
+					.>o<.
+					..#..`,
+			inputs: []Direction{Down},
+			want: `.....
+				   ..v..
+				   .>%<.
+				   ..#..`,
+		},
+		{
+			name: "slimes arriving on a slime combine in order until it is full",
+			state: `.o.o.
+					.>o<.
+					..#..`,
+			inputs: []Direction{Down},
+			want: `.....
+				   .>@o.
+				   ..#..`,
+		},
+		{
+			name:   "slimes in a line combine one at a time",
+			state:  "; slime-size = 3\nooo#",
+			inputs: []Direction{Right, Right, Right},
+			want:   `..&#`,
+		},
+		{
+			name:   "small slimes carried into each other combine on the first one",
+			state:  "oo\n><",
+			inputs: []Direction{Down},
+			want:   "..\n@<",
+		},
+		{
+			name:   "slimes carried into each other combine up to the slime size",
+			state:  "; slime-size = 3\no@\n><",
+			inputs: []Direction{Down},
+			want:   "..\n&<",
+		},
+		{
+			name:   "slimes too big to combine carried into each other stay",
+			state:  "; slime-size = 3\n@@\n><",
+			inputs: []Direction{Down},
+			want:   "..\n@@",
+		},
+		{
+			name:   "slime carried onto a slime too big to combine with",
+			state:  "o.\n>@\n.#",
+			inputs: []Direction{Down},
+			want:   "..\no@\n.#",
+		},
+		{
+			name:   "slime and box carried onto the same tile block each other",
+			state:  "..@\no.B\n>.<",
+			inputs: []Direction{Down},
+			want:   "...\n..@\no.B",
+		},
+	}
+
+	testCases(t, tt)
+}
+
+// TestMeetingSizes tries every mix of slimes carried from three sides onto a tile,
+// with and without a slime standing on it, and of slimes carried into each other,
+// against every slime size.
+func TestMeetingSizes(t *testing.T) {
+	// the slimes above, left and right arrive on the tile the middle one stands on
+	const level = "; slime-size = %d\n..%c..\n.%cv%c.\n.>%c<.\n..#.."
+	tokens := []Token{EmptyToken, SmallSlimeToken, SlimeToken}
+	for cap := 2; cap <= MaxSlimeSize; cap++ {
+		for _, above := range tokens {
+			for _, left := range tokens {
+				for _, right := range tokens {
+					for _, middle := range tokens {
+						state := fmt.Sprintf(level, cap, above, left, right, middle)
+						t.Run(fmt.Sprintf("%d %c%c%c%c", cap, above, left, right, middle), func(t *testing.T) {
+							testMeetingSizes(t, state)
+						})
+					}
+				}
+			}
+		}
+
+		for _, left := range tokens[1:] {
+			for _, right := range tokens[1:] {
+				state := fmt.Sprintf("; slime-size = %d\n%c%c\n><", cap, left, right)
+				t.Run(fmt.Sprintf("%d head on %c%c", cap, left, right), func(t *testing.T) {
+					testMeetingSizes(t, state)
+				})
+			}
+		}
+	}
+}
+
+func testMeetingSizes(t *testing.T, state string) {
+	g := NewGame(nil)
+	if err := g.Parse(state); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	before, _ := slimeSizes(g)
+	g.Move(Down)
+
+	after, tiles := slimeSizes(g)
+	if after != before {
+		t.Fatalf("expected slimes of size %d, got %d:\n%s", before, after, g)
+	}
+	for pos, n := range tiles {
+		if n > 1 {
+			t.Fatalf("expected one slime at %v, got %d:\n%s", pos, n, g)
+		}
+	}
+	if before <= g.Rules().SlimeSize && before > 0 && len(tiles) != 1 {
+		t.Fatalf("expected the slimes to combine into one:\n%s", g)
+	}
+
+	// the same move always ends the same way whatever order the states were resolved in
+	for i := 0; i < 10; i++ {
+		other := NewGame(nil)
+		if err := other.Parse(state); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		other.Move(Down)
+		if other.Key() != g.Key() {
+			t.Fatalf("expected the same result every time, got\n%s\nand\n%s", g, other)
+		}
+	}
+}
+
+// slimeSizes returns the total size of the slimes and how many slimes stand on each tile.
+func slimeSizes(g *Game) (int, map[math.Vector2]int) {
+	size := 0
+	tiles := make(map[math.Vector2]int)
+	for _, actor := range g.Actors() {
+		if s, ok := actor.(*Slime); ok {
+			size += s.Size()
+			tiles[s.GetPosition()]++
+		}
+	}
+	return size, tiles
+}
+
+func TestMeetingKeys(t *testing.T) {
+	g := NewGame(nil)
+	if err := g.Parse("oo\n.k\n><"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// the slime on the right picks up the key and combines into the slime on the left
+	g.Move(Down)
+	g.Move(Down)
+	slimes := g.Actors()
+	if len(slimes) != 1 {
+		t.Fatalf("expected one slime, got %v", slimes)
+	}
+	if s := slimes[0].(*Slime); s.Size() != 2 || s.Keys() != 1 {
+		t.Fatalf("expected a slime of size 2 with a key, got size %d with %d keys", s.Size(), s.Keys())
+	}
+}
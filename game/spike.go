@@ -32,7 +32,9 @@ func (s *Spike) Apply(g *Game, change StateChange) {
 }
 
 func (s *Spike) Tick(g *Game) {
-	s.up = !s.up
+	if g.rules.SpikesAlternate {
+		s.up = !s.up
+	}
 
 	if s.up {
 		actors := g.GetActors(s.GetPosition())
@@ -51,3 +53,8 @@ func (s *Spike) Solid() bool {
 func (s *Spike) Damage(g *Game) {
 
 }
+
+func (s *Spike) Clone() Actor {
+	c := *s
+	return &c
+}
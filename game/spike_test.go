@@ -26,3 +26,25 @@ func TestSpikes(t *testing.T) {
 
 	testCases(t, tt)
 }
+
+func TestSplitAfterBump(t *testing.T) {
+	testGame(t, testCase{
+		name:   "split after bumping into the edge",
+		state:  `@.-`,
+		inputs: []Direction{Right, Right, Down},
+		want:   `.oo`,
+	})
+}
+
+func TestSplitHeldBack(t *testing.T) {
+	// held back by a slime the slime didn't bump into anything,
+	// so it still splits off towards where it came from
+	testGame(t, testCase{
+		name: "split while held back by a slime",
+		state: `@^@
+				...`,
+		inputs: []Direction{Right, Right},
+		want: `oo@
+			   ...`,
+	})
+}
@@ -0,0 +1,138 @@
+package game
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Clone returns a deep copy of the game that can be moved independently.
+// The undo history is not copied.
+func (g *Game) Clone() *Game {
+	c := &Game{
+		board:       make([][]Token, len(g.board)),
+		actors:      make([]Actor, len(g.actors)),
+		killQueue:   make([]Actor, 0),
+		teleporters: g.teleporters,
+		rules:       g.rules,
+		turn:        g.turn,
+		log:         g.log,
+	}
+	for y, row := range g.board {
+		c.board[y] = make([]Token, len(row))
+		copy(c.board[y], row)
+	}
+	for i, actor := range g.actors {
+		c.actors[i] = actor.Clone()
+	}
+	return c
+}
+
+// CloneWithHistory is Clone but the copy can undo the moves made with Play on g.
+func (g *Game) CloneWithHistory() *Game {
+	c := g.Clone()
+	c.history = make([]*Game, len(g.history))
+	copy(c.history, g.history)
+	c.moves = make([]Direction, len(g.moves))
+	copy(c.moves, g.moves)
+	return c
+}
+
+// Key returns a string that is equal for two games in the same state.
+// Actors are compared by what they are and where they are, not by identity,
+// and the turn counter is ignored.
+func (g *Game) Key() string {
+	return g.key(true)
+}
+
+// layout is Key without where slimes came from, it is what the board looks like.
+func (g *Game) layout() string {
+	return g.key(false)
+}
+
+func (g *Game) key(lastPositions bool) string {
+	var sb strings.Builder
+	for _, row := range g.board {
+		for _, token := range row {
+			sb.WriteRune(rune(token))
+		}
+	}
+
+	actors := make([]string, len(g.actors))
+	for i, actor := range g.actors {
+		actors[i] = actorKey(actor, lastPositions)
+	}
+	sort.Strings(actors)
+	for _, a := range actors {
+		sb.WriteRune('|')
+		sb.WriteString(a)
+	}
+	return sb.String()
+}
+
+func actorKey(actor Actor, lastPosition bool) string {
+	pos := actor.GetPosition()
+	key := actor.String() + strconv.Itoa(pos.X) + "," + strconv.Itoa(pos.Y)
+
+	s, ok := actor.(*Slime)
+	if !ok {
+		return key
+	}
+	if s.keys > 0 {
+		key += "k" + strconv.Itoa(s.keys)
+	}
+	// where a slime came from decides where it splits to
+	if lastPosition {
+		key += ";" + strconv.Itoa(s.lastPosition.X) + "," + strconv.Itoa(s.lastPosition.Y)
+	}
+	return key
+}
+
+// restore replaces the state of the game with a snapshot taken by Clone.
+func (g *Game) restore(snapshot *Game) {
+	g.board = snapshot.board
+	g.actors = snapshot.actors
+	g.killQueue = make([]Actor, 0)
+	g.turn = snapshot.turn
+}
+
+// Play is MoveReport but the state before the move is remembered so it can be undone.
+func (g *Game) Play(dir Direction) Report {
+	g.history = append(g.history, g.Clone())
+	g.moves = append(g.moves, dir)
+	return g.MoveReport(dir)
+}
+
+// Undo reverts the last move made with Play.
+// It returns false if there is nothing to undo.
+func (g *Game) Undo() bool {
+	if len(g.history) == 0 {
+		return false
+	}
+
+	// snapshots can be shared with a CloneWithHistory, they are never changed
+	last := len(g.history) - 1
+	g.restore(g.history[last].Clone())
+	g.history = g.history[:last]
+	g.moves = g.moves[:last]
+	return true
+}
+
+// History returns the moves made with Play that can be undone, oldest first.
+func (g *Game) History() []Direction {
+	moves := make([]Direction, len(g.moves))
+	copy(moves, g.moves)
+	return moves
+}
+
+// Previous returns a copy of the state n moves made with Play ago,
+// or nil if fewer moves can be undone.
+func (g *Game) Previous(n int) *Game {
+	if n < 0 || n > len(g.history) {
+		return nil
+	}
+	if n == 0 {
+		return g.Clone()
+	}
+	return g.history[len(g.history)-n].Clone()
+}
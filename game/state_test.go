@@ -0,0 +1,171 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGoal(t *testing.T) {
+	tt := []struct {
+		name   string
+		state  string
+		inputs []Direction
+		won    bool
+	}{
+		{
+			name:  "no goals",
+			state: `@.`,
+			won:   false,
+		},
+		{
+			name:  "goal not reached",
+			state: `@.*`,
+			won:   false,
+		},
+		{
+			name:   "slime on goal",
+			state:  `@.*`,
+			inputs: []Direction{Right, Right},
+			won:    true,
+		},
+		{
+			name:   "box on goal",
+			state:  `@B*`,
+			inputs: []Direction{Right},
+			won:    false,
+		},
+		{
+			name:   "every goal needs a slime",
+			state:  `*@.@*`,
+			inputs: []Direction{Left},
+			won:    false,
+		},
+		{
+			name:   "small slimes count",
+			state:  `*.@-`,
+			inputs: []Direction{Right, Left, Left},
+			won:    true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGame(testLogger(t))
+			err := g.Parse(cleanState(tc.state))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, dir := range tc.inputs {
+				g.Move(dir)
+			}
+			if g.Won() != tc.won {
+				t.Fatalf("expected won to be %v\n%s", tc.won, g.String())
+			}
+		})
+	}
+}
+
+func TestClone(t *testing.T) {
+	g := NewGame(testLogger(t))
+	err := g.Parse(`@BxD.`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	c := g.Clone()
+	c.Move(Right)
+
+	if strings.TrimSpace(g.String()) != `@BxD.` {
+		t.Fatalf("expected the original game to be untouched, got\n%s", g.String())
+	}
+	if strings.TrimSpace(c.String()) != `.@B_.` {
+		t.Fatalf("expected the clone to move, got\n%s", c.String())
+	}
+	if g.Key() == c.Key() {
+		t.Fatalf("expected different keys for different states")
+	}
+}
+
+func TestKey(t *testing.T) {
+	a := NewGame(testLogger(t))
+	b := NewGame(testLogger(t))
+	if err := a.Parse(`.@.#`); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := b.Parse(`.@.#`); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	a.Move(Right)
+	b.Move(Right)
+	b.Move(Left)
+	b.Move(Right)
+	if a.Key() != b.Key() {
+		t.Fatalf("expected equal keys, got %q and %q", a.Key(), b.Key())
+	}
+
+	// bumping into the wall leaves the slime where it was
+	// but it would now split onto its own tile
+	b.Move(Right)
+	if a.Key() == b.Key() {
+		t.Fatalf("expected the last position of slimes to be part of the key")
+	}
+
+	// a slime carrying a key looks the same
+	c := NewGame(testLogger(t))
+	if err := c.Parse(`..k#`); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.AddActor(NewSlime(1, 0, false))
+	c.Move(Right)
+	if a.String() != c.String() || a.Key() == c.Key() {
+		t.Fatalf("expected keys slimes carry to be part of the key")
+	}
+}
+
+func TestUndo(t *testing.T) {
+	g := NewGame(testLogger(t))
+	err := g.Parse(`@BO.`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	start := g.Key()
+
+	if g.Undo() {
+		t.Fatalf("expected nothing to undo")
+	}
+
+	g.Play(Right)
+	g.Play(Right)
+	if strings.TrimSpace(g.String()) != `..@.` || g.Turn() != 2 {
+		t.Fatalf("expected the slime to walk over the filled pit, got\n%s", g.String())
+	}
+	if FormatMoves(g.History()) != "rr" {
+		t.Fatalf("expected history rr, got %s", FormatMoves(g.History()))
+	}
+	if g.Previous(2).Key() != start || g.Previous(0).Key() != g.Key() || g.Previous(3) != nil {
+		t.Fatalf("expected previous states to come from the history")
+	}
+
+	// undoing a copy leaves the history of the original alone
+	c := g.CloneWithHistory()
+	c.Undo()
+	c.Undo()
+	c.Play(Down)
+	if c.Key() != start || FormatMoves(c.History()) != "d" || FormatMoves(g.History()) != "rr" || g.Previous(2).Key() != start {
+		t.Fatalf("expected the copy to undo on its own")
+	}
+
+	if !g.Undo() {
+		t.Fatalf("expected undo to succeed")
+	}
+	if strings.TrimSpace(g.String()) != `.@..` || g.Turn() != 1 {
+		t.Fatalf("expected to undo the last move, got\n%s", g.String())
+	}
+
+	// the box comes back out of the pit
+	g.Undo()
+	if g.Key() != start || strings.TrimSpace(g.String()) != `@BO.` || len(g.History()) != 0 {
+		t.Fatalf("expected to be back at the start, got\n%s", g.String())
+	}
+}
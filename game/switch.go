@@ -26,15 +26,13 @@ func getDoors(g *Game) []Actor {
 	return g.GetActorsWithTokens([]Token{ClosedDoorToken, OpenDoorToken})
 }
 
-func canPressSwitch(actor Actor, change StateChange) bool {
-	token := actor.Token()
-	switch token {
-	case SlimeToken, BoxToken:
-		return true
-	case SmallSlimeToken:
-		return change.Message == "grow"
+// canPressSwitch reports whether actor presses a switch it moves onto. Crates and slimes
+// bigger than a small one do, small slimes only while growing or when the rules let them.
+func canPressSwitch(g *Game, actor Actor, change StateChange) bool {
+	if s, ok := actor.(*Slime); ok {
+		return s.size > 1 || g.rules.SmallSlimesPress || change.Message == "grow"
 	}
-	return false
+	return IsBoxToken(actor.Token())
 }
 
 func (s *Switch) Transform(g *Game, dir Direction, affectingStates AffectingStates) (*StateChange, Actor) {
@@ -42,9 +40,15 @@ func (s *Switch) Transform(g *Game, dir Direction, affectingStates AffectingStat
 	//	return nil, nil
 	//}
 
-	// check if something is moving onto the switch that can press it
-	for actor, change := range affectingStates.OnToStates {
-		canPress := canPressSwitch(actor, change)
+	// check if something is moving onto the switch that can press it. A crate stopped on
+	// the switch and the slime pushing it both move onto it, the first of them in the
+	// actors becomes the parent so the parent doesn't change between steps.
+	for _, actor := range g.actors {
+		change, ok := affectingStates.OnToStates[actor]
+		if !ok {
+			continue
+		}
+		canPress := canPressSwitch(g, actor, change)
 		if canPress {
 			return &StateChange{
 				Move:    math.NegVec, // we don't move (0 is a valid value)
@@ -89,3 +93,8 @@ func (s *Switch) Solid() bool {
 func (s *Switch) Damage(g *Game) {
 
 }
+
+func (s *Switch) Clone() Actor {
+	c := *s
+	return &c
+}
@@ -0,0 +1,148 @@
+package game
+
+import (
+	"fmt"
+	"log/slog"
+	"slimesolver/game/math"
+	"sort"
+)
+
+// Teleporter pads are written as digits, the two pads with the same digit are a pair.
+const (
+	FirstTeleporterToken Token = '0'
+	LastTeleporterToken  Token = '9'
+)
+
+// IsTeleporterToken reports whether token is a teleporter pad.
+func IsTeleporterToken(token Token) bool {
+	return token >= FirstTeleporterToken && token <= LastTeleporterToken
+}
+
+func (g *Game) IsTeleporter(x, y int) bool {
+	return IsTeleporterToken(g.GetTokenAt(x, y))
+}
+
+// Partner returns the other pad of the teleporter at pos.
+func (g *Game) Partner(pos math.Vector2) (math.Vector2, bool) {
+	partner, ok := g.teleporters[pos]
+	return partner, ok
+}
+
+// pairTeleporters finds the partner of every pad on the board.
+func (g *Game) pairTeleporters() error {
+	pads := make(map[Token][]math.Vector2)
+	for y, row := range g.board {
+		for x, token := range row {
+			if IsTeleporterToken(token) {
+				pads[token] = append(pads[token], math.Vector2{X: x, Y: y})
+			}
+		}
+	}
+
+	labels := make([]Token, 0, len(pads))
+	for label := range pads {
+		labels = append(labels, label)
+	}
+	sort.Slice(labels, func(i, j int) bool { return labels[i] < labels[j] })
+
+	g.teleporters = nil
+	for _, label := range labels {
+		l := pads[label]
+		if len(l) != 2 {
+			return fmt.Errorf("teleporter %c needs 2 pads, got %d", label, len(l))
+		}
+		if g.teleporters == nil {
+			g.teleporters = make(map[math.Vector2]math.Vector2)
+		}
+		g.teleporters[l[0]] = l[1]
+		g.teleporters[l[1]] = l[0]
+	}
+	return nil
+}
+
+type positioner interface {
+	setPosition(pos math.Vector2)
+}
+
+func (p *PositionComponent) setPosition(pos math.Vector2) {
+	p.X = pos.X
+	p.Y = pos.Y
+}
+
+// teleport moves every actor that ended its move on a pad to the partner pad, if it is free.
+// It runs once every change of the turn is applied, so a pad vacated this turn is free.
+// Whether a pad is free is decided before anyone teleports, so two actors entering the
+// two pads of a pair block each other and stay where they are.
+// A slime arriving on a slime standing on the partner pad combines into it if they fit.
+// Actors that didn't move don't teleport, otherwise they would bounce between the pads.
+func (g *Game) teleport(from map[Actor]math.Vector2) {
+	if len(g.teleporters) == 0 {
+		return
+	}
+
+	type jump struct {
+		actor Actor
+		to    math.Vector2
+	}
+	var jumps []jump
+	for _, actor := range g.actors {
+		pos := actor.GetPosition()
+		to, ok := g.teleporters[pos]
+		if !ok || pos.Equals(from[actor]) {
+			continue
+		}
+		if _, ok := actor.(positioner); !ok {
+			continue
+		}
+		if !canMoveTo(g, to, actor) {
+			if s, ok := actor.(*Slime); ok {
+				if host := g.standingSlime(to, from); host != nil && host.fits(g, s) {
+					g.teleportInto(s, host)
+				}
+			}
+			continue
+		}
+		jumps = append(jumps, jump{actor, to})
+	}
+
+	for _, j := range jumps {
+		if g.logEnabled(slog.LevelDebug) {
+			g.logAttrs(slog.LevelDebug, "teleport", slog.Int("turn", g.turn), g.actorAttr(j.actor),
+				slog.String("to", j.to.String()))
+		}
+		j.actor.(positioner).setPosition(j.to)
+		// a teleported slime splits next to the pad it came out of
+		if s, ok := j.actor.(*Slime); ok {
+			s.lastPosition = j.to
+		}
+	}
+}
+
+// standingSlime returns the slime that stood on pos since the start of the move
+// if it is the only solid actor there.
+func (g *Game) standingSlime(pos math.Vector2, from map[Actor]math.Vector2) *Slime {
+	var slime *Slime
+	for _, actor := range g.GetActors(pos) {
+		if !actor.Solid() {
+			continue
+		}
+		s, ok := actor.(*Slime)
+		if !ok || slime != nil || !from[actor].Equals(pos) {
+			return nil
+		}
+		slime = s
+	}
+	return slime
+}
+
+// teleportInto combines s into host on the partner pad, host takes its size and keys.
+func (g *Game) teleportInto(s, host *Slime) {
+	if g.logEnabled(slog.LevelDebug) {
+		g.logAttrs(slog.LevelDebug, "teleport", slog.Int("turn", g.turn), g.actorAttr(s),
+			slog.String("to", host.GetPosition().String()), slog.String("into", string(host.Token())))
+	}
+	host.size += s.size
+	host.keys += s.keys
+	s.keys = 0
+	g.Kill(s)
+}
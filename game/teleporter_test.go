@@ -0,0 +1,114 @@
+package game
+
+import "testing"
+
+func TestTeleporter(t *testing.T) {
+	tt := []testCase{
+		{
+			name:   "enter a pad",
+			state:  `@1..1`,
+			inputs: []Direction{Right},
+			want:   `.1..@`,
+		},
+		{
+			name:   "small slimes teleport",
+			state:  `o1.1`,
+			inputs: []Direction{Right},
+			want:   `.1.o`,
+		},
+		{
+			name:   "standing on a pad",
+			state:  `@1..1`,
+			inputs: []Direction{Right, Up},
+			want:   `.1..@`,
+		},
+		{
+			name:   "step off the pad",
+			state:  `@1.1.`,
+			inputs: []Direction{Right, Right},
+			want:   `.1.1@`,
+		},
+		{
+			name: "pads in different rows",
+			state: `@1.
+					..1`,
+			inputs: []Direction{Right, Left},
+			want: `.1.
+			       .@1`,
+		},
+		{
+			name:   "both pads entered at once",
+			state:  `@1.@1`,
+			inputs: []Direction{Right},
+			want:   `.@..@`,
+		},
+		{
+			name:   "partner pad vacated this turn",
+			state:  `@@2.2..`,
+			inputs: []Direction{Right, Right},
+			want:   `..2.@@.`,
+		},
+		{
+			name:   "push a box into a pad",
+			state:  `@B1..1.`,
+			inputs: []Direction{Right},
+			want:   `.@1..B.`,
+		},
+		{
+			name:   "partner pad taken",
+			state:  `@B1..1.`,
+			inputs: []Direction{Right, Right},
+			want:   `..@..B.`,
+		},
+		{
+			name:   "push a box off a pad",
+			state:  `@B1..1..`,
+			inputs: []Direction{Right, Right, Right, Right, Right},
+			want:   `..@..1B.`,
+		},
+		{
+			name:   "slime combines with a slime standing on the partner pad",
+			state:  `oo1.1`,
+			inputs: []Direction{Right, Right},
+			want:   `..1.@`,
+		},
+		{
+			name:   "slime too big to combine with on the partner pad",
+			state:  `o@1.1`,
+			inputs: []Direction{Right, Right},
+			want:   `..o.@`,
+		},
+		{
+			name:   "pairs are separate",
+			state:  `@1.2.1.2`,
+			inputs: []Direction{Right},
+			want:   `.1.2.@.2`,
+		},
+	}
+
+	testCases(t, tt)
+}
+
+func TestTeleporterPairs(t *testing.T) {
+	tt := []struct {
+		name  string
+		state string
+		err   bool
+	}{
+		{name: "pair", state: `@1.1*`},
+		{name: "lone pad", state: `@1.*`, err: true},
+		{name: "three pads", state: `@1.1.1*`, err: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := NewGame(nil).Parse(tc.state)
+			if tc.err && err == nil {
+				t.Fatalf("expected an error")
+			}
+			if !tc.err && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
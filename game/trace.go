@@ -0,0 +1,199 @@
+package game
+
+import (
+	"fmt"
+	"slimesolver/game/math"
+	"sort"
+	"strings"
+)
+
+// Trace records how a single Move was resolved.
+// It keeps every pass of building the StateChangeNode graph and every wave of
+// leaves that was popped and applied, so a turn can be inspected after the fact.
+type Trace struct {
+	Direction  Direction
+	Iterations []TraceIteration
+	Waves      [][]StateChangeLeaf
+
+	// actors are numbered by their position in the game when the move started
+	// and named by their token at that time
+	ids   map[Actor]int
+	names map[Actor]string
+}
+
+// TraceIteration is a single call to getNextStates merged into the graph.
+type TraceIteration struct {
+	// NewStates are the states returned by getNextStates
+	NewStates StateList
+	// States is the graph after NewStates were merged into it
+	States StateList
+	// Changed is false for the final iteration where the graph settled
+	Changed bool
+}
+
+func newTrace(g *Game, dir Direction) *Trace {
+	ids := make(map[Actor]int, len(g.actors))
+	names := make(map[Actor]string, len(g.actors))
+	for i, actor := range g.actors {
+		ids[actor] = i
+		names[actor] = fmt.Sprintf("%s%d", actor.String(), i)
+	}
+
+	return &Trace{
+		Direction: dir,
+		ids:       ids,
+		names:     names,
+	}
+}
+
+func copyStates(states StateList) StateList {
+	c := make(StateList, len(states))
+	for actor, node := range states {
+		c[actor] = node
+	}
+	return c
+}
+
+func (t *Trace) addIteration(newStates StateList, states StateList, changed bool) {
+	t.Iterations = append(t.Iterations, TraceIteration{
+		NewStates: copyStates(newStates),
+		States:    copyStates(states),
+		Changed:   changed,
+	})
+}
+
+func (t *Trace) addWave(leaves []StateChangeLeaf) {
+	wave := make([]StateChangeLeaf, len(leaves))
+	copy(wave, leaves)
+	t.Waves = append(t.Waves, wave)
+}
+
+// ActorID returns the number used for actor in the trace, or -1 if the actor
+// was not on the board when the move started.
+func (t *Trace) ActorID(actor Actor) int {
+	if id, ok := t.ids[actor]; ok {
+		return id
+	}
+	return -1
+}
+
+func (t *Trace) actorName(actor Actor) string {
+	if name, ok := t.names[actor]; ok {
+		return name
+	}
+	return fmt.Sprintf("%s%d", actor.String(), t.ActorID(actor))
+}
+
+// sortedActors returns the actors of states ordered by their id
+// so the DOT output is stable between runs.
+func (t *Trace) sortedActors(states StateList) []Actor {
+	actors := make([]Actor, 0, len(states))
+	for actor := range states {
+		actors = append(actors, actor)
+	}
+	sort.Slice(actors, func(i, j int) bool {
+		return t.ActorID(actors[i]) < t.ActorID(actors[j])
+	})
+	return actors
+}
+
+func (t *Trace) nodeLabel(node *StateChangeNode) string {
+	var sb strings.Builder
+	sb.WriteString(t.actorName(node.Actor))
+	if !node.Change.Move.Equals(math.NegVec) {
+		sb.WriteString(fmt.Sprintf(" %v -> %v", node.Change.From, node.Change.Move))
+	}
+	if node.Change.Message != "" {
+		sb.WriteString("\\n")
+		sb.WriteString(node.Change.Message)
+	}
+	if len(node.Change.Updates) > 0 {
+		sb.WriteString("\\nupdates:")
+		for _, actor := range node.Change.Updates {
+			sb.WriteString(" ")
+			sb.WriteString(t.actorName(actor))
+		}
+	}
+	if len(node.Change.Watching) > 0 {
+		sb.WriteString("\\nwatching:")
+		for _, actor := range node.Change.Watching {
+			sb.WriteString(" ")
+			sb.WriteString(t.actorName(actor))
+		}
+	}
+	return sb.String()
+}
+
+// writeDOTGraph writes the nodes and edges of states, node names are prefixed
+// so the same actor can appear in several clusters.
+func (t *Trace) writeDOTGraph(sb *strings.Builder, prefix string, states StateList, extra map[Actor]string) {
+	name := func(actor Actor) string {
+		return fmt.Sprintf("%s_a%d", prefix, t.ActorID(actor))
+	}
+
+	actors := t.sortedActors(states)
+	for _, actor := range actors {
+		label := t.nodeLabel(states[actor])
+		if e, ok := extra[actor]; ok {
+			label += "\\n" + e
+		}
+		sb.WriteString(fmt.Sprintf("\t\t%s [label=\"%s\"];\n", name(actor), label))
+	}
+
+	for _, actor := range actors {
+		node := states[actor]
+		if node.ParentActor != nil {
+			if _, ok := states[node.ParentActor]; ok {
+				sb.WriteString(fmt.Sprintf("\t\t%s -> %s;\n", name(actor), name(node.ParentActor)))
+			}
+		}
+		for _, update := range node.Change.Updates {
+			if _, ok := states[update]; ok {
+				sb.WriteString(fmt.Sprintf("\t\t%s -> %s [style=dashed, label=\"updates\"];\n", name(actor), name(update)))
+			}
+		}
+		for _, watch := range node.Change.Watching {
+			if _, ok := states[watch]; ok {
+				sb.WriteString(fmt.Sprintf("\t\t%s -> %s [style=dotted, label=\"watching\"];\n", name(actor), name(watch)))
+			}
+		}
+	}
+}
+
+// DOT returns the trace as a Graphviz graph.
+// Every iteration of resolving the move is drawn as its own cluster followed by
+// the graph that was applied, labeled with the wave each node was popped in.
+// Solid edges point from a node to its Parent which is applied after it.
+func (t *Trace) DOT() string {
+	var sb strings.Builder
+	sb.WriteString("digraph trace {\n")
+	sb.WriteString(fmt.Sprintf("\tlabel=\"move %s\";\n", dirString(t.Direction)))
+	sb.WriteString("\tnode [shape=box, fontname=\"monospace\"];\n")
+
+	for i, iteration := range t.Iterations {
+		sb.WriteString(fmt.Sprintf("\tsubgraph cluster_iteration_%d {\n", i+1))
+		label := fmt.Sprintf("iteration %d", i+1)
+		if !iteration.Changed {
+			label += " (settled)"
+		}
+		sb.WriteString(fmt.Sprintf("\t\tlabel=\"%s\";\n", label))
+		t.writeDOTGraph(&sb, fmt.Sprintf("i%d", i+1), iteration.States, nil)
+		sb.WriteString("\t}\n")
+	}
+
+	applied := make(StateList)
+	waves := make(map[Actor]string)
+	for i, wave := range t.Waves {
+		for _, leaf := range wave {
+			applied[leaf.Actor] = leaf.Node
+			waves[leaf.Actor] = fmt.Sprintf("wave %d", i)
+		}
+	}
+	sb.WriteString("\tsubgraph cluster_apply {\n")
+	sb.WriteString("\t\tlabel=\"apply\";\n")
+	t.writeDOTGraph(&sb, "apply", applied, waves)
+	sb.WriteString("\t}\n")
+
+	sb.WriteString("}\n")
+	return sb.String()
+}
@@ -0,0 +1,76 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMoveTrace(t *testing.T) {
+	g := NewGame(nil)
+	err := g.Parse(`@B.`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	actors := g.Actors()
+	slime, box := actors[0], actors[1]
+
+	trace := g.MoveTrace(Right)
+
+	if g.String() != ".@B\n" {
+		t.Fatalf("expected trace to apply the move, got\n%s", g.String())
+	}
+
+	// the slime moves, then the box notices the push, then nothing changes
+	if len(trace.Iterations) != 3 {
+		t.Fatalf("expected 3 iterations, got %d", len(trace.Iterations))
+	}
+	if trace.Iterations[2].Changed {
+		t.Fatalf("expected the last iteration to settle")
+	}
+	if trace.Iterations[0].States[box].Parent != nil {
+		t.Fatalf("expected the box to have no parent in the first iteration")
+	}
+	if trace.Iterations[1].States[box].ParentActor != slime {
+		t.Fatalf("expected the slime to become the parent of the box")
+	}
+
+	// the box is applied before the slime that pushes it
+	if len(trace.Waves) != 2 || trace.Waves[0][0].Actor != box || trace.Waves[1][0].Actor != slime {
+		t.Fatalf("expected the box to be applied before the slime, got %v", trace.Waves)
+	}
+
+	dot := trace.DOT()
+	for _, want := range []string{
+		"digraph trace {",
+		`label="move right";`,
+		"subgraph cluster_iteration_3 {",
+		`label="iteration 3 (settled)";`,
+		`apply_a0 [label="@0 (0, 0) -> (1, 0)\nwave 1"];`,
+		`apply_a1 [label="B1 (1, 0) -> (2, 0)\nwave 0"];`,
+		"apply_a1 -> apply_a0;",
+	} {
+		if !strings.Contains(dot, want) {
+			t.Fatalf("expected %q in\n%s", want, dot)
+		}
+	}
+}
+
+func TestMoveTraceUpdates(t *testing.T) {
+	g := NewGame(nil)
+	err := g.Parse(`@xD`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	dot := g.MoveTrace(Right).DOT()
+	for _, want := range []string{
+		`apply_a1 [label="x1\nupdates: D2\nwave 1"];`,
+		`apply_a2 [label="D2\nopen\nwave 0"];`,
+		`apply_a1 -> apply_a2 [style=dashed, label="updates"];`,
+		`apply_a2 -> apply_a1;`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Fatalf("expected %q in\n%s", want, dot)
+		}
+	}
+}
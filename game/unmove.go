@@ -0,0 +1,217 @@
+package game
+
+import "slimesolver/game/math"
+
+// Unmove plays a move backwards. It returns states that Move(dir) turns into g.
+// Slimes step back or stay where they are, big slimes pull the box in front of them
+// or pull a box back out of the pit it filled, slimes that split on a spike merge
+// again and slimes split into the two slimes they combined from.
+// Every state returned is checked by moving it forward, but not every state is found:
+// switches, doors, keys, teleporters, ice, conveyors, crumbling floor and actors that
+// died aren't played backwards, and at most one split is merged per move.
+// Slimes in the returned states came from their own tile, where they split
+// to depends on that.
+func (g *Game) Unmove(dir Direction) []*Game {
+	want := g.layout()
+	seen := make(map[string]bool)
+	var found []*Game
+	for _, unticked := range g.untick() {
+		for _, c := range unticked.unmoveSlimes(dir) {
+			if !c.settled() {
+				continue
+			}
+			next := c.Clone()
+			next.Move(dir)
+			if next.layout() != want {
+				continue
+			}
+
+			key := c.layout()
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			c.turn = max(g.turn-1, 0)
+			found = append(found, c)
+		}
+	}
+	return found
+}
+
+// settled reports whether no two solid actors share a tile and no actor is on a wall or a pit,
+// which a state reached by moving can't have. Slimes stand on pits that don't kill them.
+func (g *Game) settled() bool {
+	taken := make(map[math.Vector2]bool)
+	for _, actor := range g.actors {
+		pos := actor.GetPosition()
+		if g.IsWallOrEdge(pos.X, pos.Y) {
+			return false
+		}
+		if g.IsPit(pos.X, pos.Y) && (g.rules.PitsKill || !isSlime(actor)) {
+			return false
+		}
+		if actor.Solid() {
+			if taken[pos] {
+				return false
+			}
+			taken[pos] = true
+		}
+	}
+	return true
+}
+
+// untick returns the states before spikes flipped and slimes split on them.
+func (g *Game) untick() []*Game {
+	base := g.Clone()
+	var raised []math.Vector2
+	for _, actor := range base.actors {
+		if spike, ok := actor.(*Spike); ok {
+			if spike.up {
+				raised = append(raised, spike.GetPosition())
+			}
+			if g.rules.SpikesAlternate {
+				spike.up = !spike.up
+			}
+		}
+	}
+
+	states := []*Game{base}
+	for _, pos := range raised {
+		for i, actor := range base.actors {
+			s, ok := actor.(*Slime)
+			if !ok || !s.GetPosition().Equals(pos) {
+				continue
+			}
+
+			// the split didn't fit anywhere, the slime kept the bigger half of an even or odd size
+			for _, size := range []int{2 * s.size, 2*s.size - 1} {
+				if size < 2 || size > g.rules.SlimeSize {
+					continue
+				}
+				c := base.Clone()
+				c.actors[i].(*Slime).size = size
+				states = append(states, c)
+			}
+
+			for j, other := range base.actors {
+				o, ok := other.(*Slime)
+				if !ok || i == j || !isNeighbour(pos, o.GetPosition()) {
+					continue
+				}
+				if (o.size != s.size && o.size != s.size-1) || !s.fits(g, o) {
+					continue
+				}
+				c := base.Clone()
+				c.actors[i].(*Slime).size = s.size + o.size
+				c.RemoveActor(c.actors[j])
+				states = append(states, c)
+			}
+		}
+	}
+	return states
+}
+
+func isNeighbour(a, b math.Vector2) bool {
+	for _, dir := range []Direction{Up, Down, Left, Right} {
+		if moveVector(a, dir).Equals(b) {
+			return true
+		}
+	}
+	return false
+}
+
+// unmoveSlimes returns every combination of ways the slimes of g could have moved to where they are.
+func (g *Game) unmoveSlimes(dir Direction) []*Game {
+	var slimes []int
+	for i, actor := range g.actors {
+		if isSlime(actor) {
+			slimes = append(slimes, i)
+		}
+	}
+
+	states := []*Game{g.Clone()}
+	for _, i := range slimes {
+		var next []*Game
+		for _, c := range states {
+			next = append(next, c.unmoveSlime(i, dir)...)
+		}
+		states = next
+	}
+	return states
+}
+
+// unmoveSlime returns the states where the slime at index i of the actors stayed or moved to its tile.
+// New actors are added at the end so the indexes of the other slimes don't change.
+func (g *Game) unmoveSlime(i int, dir Direction) []*Game {
+	s := g.actors[i].(*Slime)
+	pos := s.GetPosition()
+	back := moveVector(pos, opposite(dir))
+	front := moveVector(pos, dir)
+
+	stay := g.Clone()
+	stay.actors[i].(*Slime).lastPosition = pos
+	states := []*Game{stay}
+	if g.IsWallOrEdge(back.X, back.Y) {
+		return states
+	}
+
+	retreat := func() *Game {
+		c := g.Clone()
+		slime := c.actors[i].(*Slime)
+		slime.X, slime.Y = back.X, back.Y
+		slime.lastPosition = back
+		return c
+	}
+
+	states = append(states, retreat())
+	if s.size == 1 {
+		return states
+	}
+
+	// pull the box the slime pushed
+	for j, actor := range g.actors {
+		if IsBoxToken(actor.Token()) && actor.GetPosition().Equals(front) {
+			c := retreat()
+			box := c.actors[j].(*Box)
+			box.X, box.Y = pos.X, pos.Y
+			states = append(states, c)
+		}
+	}
+
+	// pull a box out of the pit it filled
+	if !g.IsWallOrEdge(front.X, front.Y) && g.GetTokenAt(front.X, front.Y) == EmptyToken && len(g.GetActors(front)) == 0 {
+		c := retreat()
+		c.SetTokenAt(front.X, front.Y, PitToken)
+		c.AddActor(NewBox(pos.X, pos.Y))
+		states = append(states, c)
+	}
+
+	// split into the slime that stayed and the one that moved onto it
+	for size := 1; size < s.size; size++ {
+		c := g.Clone()
+		slime := c.actors[i].(*Slime)
+		slime.size = s.size - size
+		slime.lastPosition = pos
+		moved := NewSizedSlime(back.X, back.Y, size)
+		moved.lastPosition = back
+		c.AddActor(moved)
+		states = append(states, c)
+	}
+
+	return states
+}
+
+func opposite(dir Direction) Direction {
+	switch dir {
+	case Up:
+		return Down
+	case Down:
+		return Up
+	case Left:
+		return Right
+	case Right:
+		return Left
+	default:
+		return dir
+	}
+}
@@ -0,0 +1,94 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnmove(t *testing.T) {
+	tt := []struct {
+		name     string
+		state    string
+		inputs   []Direction
+		dir      Direction
+		previous []string
+	}{
+		{
+			name:     "step back",
+			state:    `.@.`,
+			dir:      Left,
+			previous: []string{`..@`},
+		},
+		{
+			name:     "blocked by the edge",
+			state:    `@..`,
+			dir:      Left,
+			previous: []string{`@..`, `.@.`},
+		},
+		{
+			name:     "pull a box",
+			state:    `B@.`,
+			dir:      Left,
+			previous: []string{`.B@`},
+		},
+		{
+			name:     "pull a box out of a pit",
+			state:    `.@..`,
+			dir:      Left,
+			previous: []string{`OB@.`},
+		},
+		{
+			name:     "merge a split",
+			state:    `.@-`,
+			inputs:   []Direction{Right},
+			dir:      Right,
+			previous: []string{`.@-`},
+		},
+		{
+			name:     "split a combine",
+			state:    `.@#`,
+			dir:      Right,
+			previous: []string{`oo#`},
+		},
+		{
+			name:     "split a combine of bigger slimes",
+			state:    "; slime-size = 4\n.%#",
+			dir:      Right,
+			previous: []string{`o&#`, `@@#`},
+		},
+		{
+			name:     "merge a split of a huge slime",
+			state:    "; slime-size = 4\n.%-",
+			inputs:   []Direction{Right},
+			dir:      Right,
+			previous: []string{`.%-`},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGame(nil)
+			if err := g.Parse(tc.state); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, dir := range tc.inputs {
+				g.Move(dir)
+			}
+			want := g.String()
+
+			found := make(map[string]bool)
+			for _, previous := range g.Unmove(tc.dir) {
+				found[strings.TrimSpace(previous.String())] = true
+				previous.Move(tc.dir)
+				if previous.String() != want {
+					t.Fatalf("expected the previous state to move to\n%s\ngot\n%s", want, previous.String())
+				}
+			}
+			for _, previous := range tc.previous {
+				if !found[previous] {
+					t.Fatalf("expected %s to be a previous state, got %v", previous, found)
+				}
+			}
+		})
+	}
+}
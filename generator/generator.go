@@ -0,0 +1,258 @@
+// Package generator makes random levels and keeps the ones the solver proves are good puzzles.
+package generator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"slimesolver/game"
+	"slimesolver/solver"
+	"slimesolver/stats"
+	"strings"
+)
+
+// Mechanic is something a level can require its solution to do.
+type Mechanic string
+
+const (
+	// Split is a slime splitting in two on a spike
+	Split Mechanic = "split"
+	// Combine is two small slimes growing into one
+	Combine Mechanic = "combine"
+	// Push is a slime pushing a box
+	Push Mechanic = "push"
+	// Switch is a door opened by a switch
+	Switch Mechanic = "switch"
+)
+
+// Mechanics lists every mechanic.
+var Mechanics = []Mechanic{Split, Combine, Push, Switch}
+
+// ParseMechanic returns the mechanic called name.
+func ParseMechanic(name string) (Mechanic, error) {
+	for _, m := range Mechanics {
+		if string(m) == name {
+			return m, nil
+		}
+	}
+	return "", fmt.Errorf("unknown mechanic: %s", name)
+}
+
+// ErrAttempts is returned when not enough levels passed within Options.Attempts.
+var ErrAttempts = errors.New("ran out of attempts")
+
+const (
+	DefaultAttempts  = 1000
+	DefaultMaxStates = 20000
+	DefaultWalls     = 0.2
+	DefaultSteps     = 30
+)
+
+type Options struct {
+	Width, Height int
+	Seed          int64
+	// Count is how many levels to generate
+	Count int
+	// Attempts bounds the number of random levels tried
+	Attempts int
+
+	// Walls is the share of tiles that are walls
+	Walls float64
+	// how many of each to place, Reverse places no pits but pulls up to Pits boxes out of pits
+	Slimes, Pits, Boxes, Switches, Doors, Spikes int
+	// Goals defaults to one per slime, more goals than slimes need a split.
+	// Reverse starts with a slime on every goal.
+	Goals int
+	// Steps is how many moves Reverse plays backwards from the solved level
+	Steps int
+
+	// MinLength and MaxLength bound the shortest solution, 0 means no bound
+	MinLength, MaxLength int
+	// MinScore and MaxScore bound the difficulty score of stats, 0 means no bound
+	MinScore, MaxScore float64
+	// Require lists mechanics every solution has to use
+	Require []Mechanic
+
+	// MaxStates bounds each search, levels that need more are skipped
+	MaxStates int
+}
+
+func (opts Options) withDefaults() Options {
+	if opts.Count <= 0 {
+		opts.Count = 1
+	}
+	if opts.Attempts <= 0 {
+		opts.Attempts = DefaultAttempts
+	}
+	if opts.MaxStates <= 0 {
+		opts.MaxStates = DefaultMaxStates
+	}
+	if opts.Slimes <= 0 {
+		opts.Slimes = 1
+	}
+	if opts.Goals <= 0 {
+		opts.Goals = opts.Slimes
+	}
+	if opts.Steps <= 0 {
+		opts.Steps = DefaultSteps
+	}
+	return opts
+}
+
+// validate checks that the board has room for things tiles with something on them.
+func (opts Options) validate(things int) error {
+	if opts.Width <= 0 || opts.Height <= 0 {
+		return errors.New("width and height have to be positive")
+	}
+	if opts.Width*opts.Height < things {
+		return errors.New("too many things for the size of the level")
+	}
+	return nil
+}
+
+// Level is a generated level that passed every check.
+type Level struct {
+	// Data is the level in the format Game.Parse reads
+	Data string
+	// Seed builds the same level again with Build, or with Scramble for levels from Reverse
+	Seed int64
+	// Stats of levels from Reverse only have the known solution unless a score was bounded
+	Stats stats.Stats
+	// Mechanics the shortest solution uses
+	Mechanics []Mechanic
+}
+
+// Generate tries random levels until opts.Count of them pass.
+// The same options always generate the same levels.
+func Generate(ctx context.Context, opts Options) ([]Level, error) {
+	opts = opts.withDefaults()
+	if err := opts.validate(opts.Slimes + opts.Goals + opts.Pits + opts.Boxes + opts.Switches + opts.Doors + opts.Spikes); err != nil {
+		return nil, err
+	}
+
+	rng := rand.New(rand.NewSource(opts.Seed))
+	var levels []Level
+	for attempt := 0; attempt < opts.Attempts && len(levels) < opts.Count; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return levels, err
+		}
+
+		seed := rng.Int63()
+		level, ok, err := check(ctx, Build(seed, opts), opts)
+		if err != nil {
+			return levels, err
+		}
+		if ok {
+			level.Seed = seed
+			levels = append(levels, level)
+		}
+	}
+
+	if len(levels) < opts.Count {
+		return levels, ErrAttempts
+	}
+	return levels, nil
+}
+
+// Build lays out a random level from seed, it isn't checked.
+func Build(seed int64, opts Options) string {
+	opts = opts.withDefaults()
+	rng := rand.New(rand.NewSource(seed))
+
+	grid := make([][]game.Token, opts.Height)
+	var free []int
+	for y := range grid {
+		grid[y] = make([]game.Token, opts.Width)
+		for x := range grid[y] {
+			grid[y][x] = game.EmptyToken
+			free = append(free, y*opts.Width+x)
+		}
+	}
+	rng.Shuffle(len(free), func(i, j int) { free[i], free[j] = free[j], free[i] })
+
+	place := func(token game.Token, n int) {
+		for ; n > 0 && len(free) > 0; n-- {
+			i := free[0]
+			free = free[1:]
+			grid[i/opts.Width][i%opts.Width] = token
+		}
+	}
+	place(game.SlimeToken, opts.Slimes)
+	place(game.GoalToken, opts.Goals)
+	place(game.PitToken, opts.Pits)
+	place(game.BoxToken, opts.Boxes)
+	place(game.SwitchToken, opts.Switches)
+	place(game.ClosedDoorToken, opts.Doors)
+	place(game.SpikeDownToken, opts.Spikes)
+	place(game.WallToken, int(opts.Walls*float64(len(free))))
+
+	return format(grid)
+}
+
+func format(grid [][]game.Token) string {
+	var sb strings.Builder
+	for y, row := range grid {
+		if y > 0 {
+			sb.WriteRune('\n')
+		}
+		for _, token := range row {
+			sb.WriteRune(rune(token))
+		}
+	}
+	return sb.String()
+}
+
+// check solves a level and returns it if it meets opts.
+func check(ctx context.Context, data string, opts Options) (Level, bool, error) {
+	g := game.NewGame(nil)
+	if err := g.Parse(data); err != nil {
+		return Level{}, false, err
+	}
+	if g.Won() {
+		return Level{}, false, nil
+	}
+	if _, dead := solver.NewAnalyzer(g).Check(g); dead {
+		return Level{}, false, nil
+	}
+
+	s, err := stats.Compute(ctx, g, stats.Options{MaxStates: opts.MaxStates})
+	if err != nil {
+		return Level{}, false, err
+	}
+	return judge(ctx, g, data, s, opts)
+}
+
+// judge returns the level if its stats and solution meet opts.
+func judge(ctx context.Context, g *game.Game, data string, s stats.Stats, opts Options) (Level, bool, error) {
+	if !s.Solvable ||
+		opts.MinLength > 0 && s.Length < opts.MinLength ||
+		opts.MaxLength > 0 && s.Length > opts.MaxLength ||
+		opts.MinScore > 0 && s.Score < opts.MinScore ||
+		opts.MaxScore > 0 && s.Score > opts.MaxScore {
+		return Level{}, false, nil
+	}
+
+	moves, err := game.ParseMoves(s.Solution)
+	if err != nil {
+		return Level{}, false, err
+	}
+	used := uses(g, moves)
+	for _, m := range opts.Require {
+		if !used[m] {
+			return Level{}, false, nil
+		}
+		required, err := requires(ctx, g, m, opts.MaxStates)
+		if err != nil || !required {
+			return Level{}, false, err
+		}
+	}
+
+	level := Level{Data: data, Stats: s}
+	for _, m := range Mechanics {
+		if used[m] {
+			level.Mechanics = append(level.Mechanics, m)
+		}
+	}
+	return level, true, nil
+}
@@ -0,0 +1,127 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"slimesolver/game"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	tt := []struct {
+		name string
+		opts Options
+	}{
+		{
+			name: "push",
+			opts: Options{Width: 5, Height: 3, Seed: 1, Count: 2, Boxes: 1, Pits: 1, Require: []Mechanic{Push}},
+		},
+		{
+			name: "split",
+			opts: Options{Width: 4, Height: 3, Seed: 1, Count: 1, Goals: 2, Spikes: 1, Require: []Mechanic{Split}},
+		},
+		{
+			name: "long",
+			opts: Options{Width: 5, Height: 3, Seed: 3, Count: 2, Boxes: 1, Pits: 1, Walls: DefaultWalls, MinLength: 6, MaxLength: 8},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			levels, err := Generate(context.Background(), tc.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(levels) != tc.opts.Count {
+				t.Fatalf("expected %d levels, got %d", tc.opts.Count, len(levels))
+			}
+
+			for _, level := range levels {
+				if Build(level.Seed, tc.opts) != level.Data {
+					t.Fatalf("expected the seed to build the same level")
+				}
+				if tc.opts.MinLength > 0 && level.Stats.Length < tc.opts.MinLength || tc.opts.MaxLength > 0 && level.Stats.Length > tc.opts.MaxLength {
+					t.Fatalf("expected a solution of %d to %d moves, got %d", tc.opts.MinLength, tc.opts.MaxLength, level.Stats.Length)
+				}
+				for _, m := range tc.opts.Require {
+					found := false
+					for _, used := range level.Mechanics {
+						found = found || used == m
+					}
+					if !found {
+						t.Fatalf("expected the solution to use %s, got %v", m, level.Mechanics)
+					}
+				}
+
+				g := game.NewGame(nil)
+				if err := g.Parse(level.Data); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				moves, err := game.ParseMoves(level.Stats.Solution)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				for _, dir := range moves {
+					g.Move(dir)
+				}
+				if !g.Won() {
+					t.Fatalf("expected %s to solve\n%s", level.Stats.Solution, level.Data)
+				}
+			}
+
+			// the same seed generates the same levels
+			again, err := Generate(context.Background(), tc.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for i := range levels {
+				if again[i].Data != levels[i].Data {
+					t.Fatalf("expected the same levels from the same seed")
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	_, err := Generate(context.Background(), Options{Width: 4, Height: 1, Seed: 1, Attempts: 20, Require: []Mechanic{Switch}})
+	if !errors.Is(err, ErrAttempts) {
+		t.Fatalf("expected to run out of attempts, got %v", err)
+	}
+
+	_, err = Generate(context.Background(), Options{Width: 2, Height: 1, Boxes: 1})
+	if err == nil {
+		t.Fatalf("expected too many things to fit")
+	}
+}
+
+func TestRequires(t *testing.T) {
+	tt := []struct {
+		name  string
+		state string
+		m     Mechanic
+		want  bool
+	}{
+		{"box in the way", `@BO*`, Push, true},
+		{"box beside the way", `@.*
+								 BO.`, Push, false},
+		{"one slime two goals", `@-*
+								 ..*`, Split, true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			g := game.NewGame(nil)
+			if err := g.Parse(tc.state); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, err := requires(context.Background(), g, tc.m, DefaultMaxStates)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("expected requires %s to be %v", tc.m, tc.want)
+			}
+		})
+	}
+}
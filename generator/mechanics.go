@@ -0,0 +1,81 @@
+package generator
+
+import (
+	"context"
+	"slimesolver/game"
+	"slimesolver/solver"
+)
+
+// mechanics returns what happened during a move.
+func mechanics(report game.Report) map[Mechanic]bool {
+	m := make(map[Mechanic]bool)
+	for _, a := range report.Spawned {
+		if game.IsSlimeToken(a.Token) {
+			m[Split] = true
+		}
+	}
+	for _, a := range report.Changed {
+		switch {
+		case game.IsSlimeToken(a.Token) && game.SlimeSize(a.NewToken) > game.SlimeSize(a.Token):
+			m[Combine] = true
+		case a.Token == game.ClosedDoorToken && a.NewToken == game.OpenDoorToken:
+			m[Switch] = true
+		}
+	}
+	// boxes pushed into a pit are killed where they fell
+	for _, events := range [][]game.ActorEvent{report.Moved, report.Killed} {
+		for _, a := range events {
+			if game.IsBoxToken(a.Token) && !a.From.Equals(a.To) {
+				m[Push] = true
+			}
+		}
+	}
+	return m
+}
+
+// uses replays moves from g and returns every mechanic they use.
+func uses(g *game.Game, moves []game.Direction) map[Mechanic]bool {
+	used := make(map[Mechanic]bool)
+	current := g.Clone()
+	for _, dir := range moves {
+		for m := range mechanics(current.MoveReport(dir)) {
+			used[m] = true
+		}
+	}
+	return used
+}
+
+// requires reports whether every solution of g uses m, by searching for one that doesn't.
+// A search that runs out of states proves nothing so it reports false.
+func requires(ctx context.Context, g *game.Game, m Mechanic, maxStates int) (bool, error) {
+	visited := map[string]bool{g.Key(): true}
+	queue := []*game.Game{g.Clone()}
+	for len(queue) > 0 {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+
+		current := queue[0]
+		queue = queue[1:]
+		for _, dir := range solver.Directions {
+			next := current.Clone()
+			if mechanics(next.MoveReport(dir))[m] {
+				continue
+			}
+			if next.Won() {
+				return false, nil
+			}
+
+			key := next.Key()
+			if visited[key] || solver.Lost(next) {
+				continue
+			}
+			if len(visited) >= maxStates {
+				return false, nil
+			}
+			visited[key] = true
+			queue = append(queue, next)
+		}
+	}
+	return true, nil
+}
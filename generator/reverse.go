@@ -0,0 +1,207 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"slimesolver/game"
+	"slimesolver/solver"
+	"slimesolver/stats"
+	"strings"
+)
+
+// Reverse generates levels by playing moves backwards from a solved level with Game.Unmove,
+// so every level can be solved and comes with a solution without searching for one.
+// The solution is shortened with solver.Minimize but it isn't always the shortest.
+// Levels are only explored with stats.Compute when a score is bounded.
+// The same options always generate the same levels.
+func Reverse(ctx context.Context, opts Options) ([]Level, error) {
+	opts = opts.withDefaults()
+	if err := opts.validate(opts.Goals + opts.Boxes + opts.Spikes); err != nil {
+		return nil, err
+	}
+	if opts.Switches > 0 || opts.Doors > 0 {
+		return nil, errors.New("switches and doors can't be played backwards")
+	}
+
+	rng := rand.New(rand.NewSource(opts.Seed))
+	var levels []Level
+	for attempt := 0; attempt < opts.Attempts && len(levels) < opts.Count; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return levels, err
+		}
+
+		seed := rng.Int63()
+		data, moves := Scramble(seed, opts)
+		if data == "" {
+			continue
+		}
+		level, ok, err := checkScrambled(ctx, data, moves, opts)
+		if err != nil {
+			return levels, err
+		}
+		if ok {
+			level.Seed = seed
+			levels = append(levels, level)
+		}
+	}
+
+	if len(levels) < opts.Count {
+		return levels, ErrAttempts
+	}
+	return levels, nil
+}
+
+// Scramble lays out a solved level from seed, with a slime on every goal, and plays
+// up to opts.Steps random moves backwards from it, never with more slimes than opts.Slimes
+// or opts.Goals. It returns the level and the moves
+// that take it back to the solved level, or an empty string when the level can't be
+// written down because actors share a tile or stand on a goal. It isn't checked.
+func Scramble(seed int64, opts Options) (string, []game.Direction) {
+	opts = opts.withDefaults()
+	rng := rand.New(rand.NewSource(seed))
+
+	grid := make([][]game.Token, opts.Height)
+	var free []int
+	for y := range grid {
+		grid[y] = make([]game.Token, opts.Width)
+		for x := range grid[y] {
+			grid[y][x] = game.EmptyToken
+			free = append(free, y*opts.Width+x)
+		}
+	}
+	rng.Shuffle(len(free), func(i, j int) { free[i], free[j] = free[j], free[i] })
+
+	var goals []int
+	place := func(token game.Token, n int) {
+		for ; n > 0 && len(free) > 0; n-- {
+			i := free[0]
+			free = free[1:]
+			grid[i/opts.Width][i%opts.Width] = token
+			if token == game.GoalToken {
+				goals = append(goals, i)
+			}
+		}
+	}
+	place(game.GoalToken, opts.Goals)
+	place(game.BoxToken, opts.Boxes)
+	for n := 0; n < opts.Spikes; n++ {
+		if rng.Intn(2) == 0 {
+			place(game.SpikeUpToken, 1)
+		} else {
+			place(game.SpikeDownToken, 1)
+		}
+	}
+	place(game.WallToken, int(opts.Walls*float64(len(free))))
+
+	g := game.NewGame(nil)
+	if err := g.Parse(format(grid)); err != nil {
+		return "", nil
+	}
+	for _, i := range goals {
+		g.AddActor(game.NewSlime(i%opts.Width, i/opts.Width, false))
+	}
+
+	type previous struct {
+		dir  game.Direction
+		game *game.Game
+	}
+	maxSlimes := max(opts.Slimes, opts.Goals)
+	visited := map[string]bool{g.Key(): true}
+	var reversed []game.Direction
+	for step := 0; step < opts.Steps; step++ {
+		var options []previous
+		for _, dir := range solver.Directions {
+			for _, p := range g.Unmove(dir) {
+				slimes := len(p.GetActorsWithTokens(game.SlimeTokens()))
+				if !visited[p.Key()] && countPits(p) <= opts.Pits && slimes <= maxSlimes {
+					options = append(options, previous{dir, p})
+				}
+			}
+		}
+		if len(options) == 0 {
+			break
+		}
+
+		chosen := options[rng.Intn(len(options))]
+		visited[chosen.game.Key()] = true
+		reversed = append(reversed, chosen.dir)
+		g = chosen.game
+	}
+
+	if !writable(g) {
+		return "", nil
+	}
+	moves := make([]game.Direction, len(reversed))
+	for i, dir := range reversed {
+		moves[len(moves)-1-i] = dir
+	}
+	return strings.TrimSpace(g.String()), moves
+}
+
+func countPits(g *game.Game) int {
+	n := 0
+	for y := 0; y < g.Height(); y++ {
+		for x := 0; x < g.Width(); x++ {
+			if g.IsPit(x, y) {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// writable reports whether the level format can hold g, which is when no tile has
+// more than one actor and actors are only on empty tiles.
+func writable(g *game.Game) bool {
+	for _, actor := range g.Actors() {
+		pos := actor.GetPosition()
+		if len(g.GetActors(pos)) > 1 || g.GetTokenAt(pos.X, pos.Y) != game.EmptyToken {
+			return false
+		}
+	}
+	return true
+}
+
+// checkScrambled replays moves on a scrambled level and returns it if it meets opts.
+// The replay can miss the solved level when a slime splits somewhere else than it
+// did backwards, those levels are skipped.
+func checkScrambled(ctx context.Context, data string, moves []game.Direction, opts Options) (Level, bool, error) {
+	g := game.NewGame(nil)
+	if err := g.Parse(data); err != nil {
+		return Level{}, false, err
+	}
+	if g.Won() {
+		return Level{}, false, nil
+	}
+
+	n := -1
+	current := g.Clone()
+	for i, dir := range moves {
+		current.Move(dir)
+		if current.Won() {
+			n = i + 1
+			break
+		}
+	}
+	if n == -1 {
+		return Level{}, false, nil
+	}
+
+	shorter, err := solver.Minimize(ctx, g, moves[:n], solver.Options{MaxNodes: opts.MaxStates, Algorithm: solver.AStar})
+	if err != nil {
+		return Level{}, false, err
+	}
+	if err := ctx.Err(); err != nil {
+		return Level{}, false, err
+	}
+
+	s := stats.Stats{Solvable: true, Length: len(shorter.Moves), Solution: game.FormatMoves(shorter.Moves)}
+	if opts.MinScore > 0 || opts.MaxScore > 0 {
+		s, err = stats.Compute(ctx, g, stats.Options{MaxStates: opts.MaxStates})
+		if err != nil {
+			return Level{}, false, err
+		}
+	}
+	return judge(ctx, g, data, s, opts)
+}
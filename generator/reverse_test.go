@@ -0,0 +1,110 @@
+package generator
+
+import (
+	"context"
+	"slimesolver/game"
+	"testing"
+)
+
+func TestReverse(t *testing.T) {
+	tt := []struct {
+		name string
+		opts Options
+	}{
+		{
+			name: "walk",
+			opts: Options{Width: 6, Height: 4, Seed: 1, Count: 2, MinLength: 3},
+		},
+		{
+			name: "push",
+			opts: Options{Width: 6, Height: 4, Seed: 3, Count: 2, Walls: DefaultWalls, Boxes: 2, Pits: 1, Require: []Mechanic{Push}, MinLength: 5},
+		},
+		{
+			name: "spikes",
+			opts: Options{Width: 6, Height: 4, Seed: 3, Count: 2, Goals: 2, Spikes: 1, MaxLength: 10},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			levels, err := Reverse(context.Background(), tc.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(levels) != tc.opts.Count {
+				t.Fatalf("expected %d levels, got %d", tc.opts.Count, len(levels))
+			}
+
+			for _, level := range levels {
+				if data, _ := Scramble(level.Seed, tc.opts); data != level.Data {
+					t.Fatalf("expected the seed to scramble the same level")
+				}
+				if tc.opts.MinLength > 0 && level.Stats.Length < tc.opts.MinLength || tc.opts.MaxLength > 0 && level.Stats.Length > tc.opts.MaxLength {
+					t.Fatalf("expected a solution of %d to %d moves, got %d", tc.opts.MinLength, tc.opts.MaxLength, level.Stats.Length)
+				}
+
+				moves, err := game.ParseMoves(level.Stats.Solution)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if len(moves) != level.Stats.Length {
+					t.Fatalf("expected a solution of %d moves, got %s", level.Stats.Length, level.Stats.Solution)
+				}
+				if !solves(t, level.Data, moves) {
+					t.Fatalf("expected %s to solve\n%s", level.Stats.Solution, level.Data)
+				}
+			}
+
+			again, err := Reverse(context.Background(), tc.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for i := range levels {
+				if again[i].Data != levels[i].Data {
+					t.Fatalf("expected the same levels from the same seed")
+				}
+			}
+		})
+	}
+}
+
+func TestScramble(t *testing.T) {
+	opts := Options{Width: 5, Height: 4, Walls: DefaultWalls, Goals: 2, Boxes: 2, Pits: 2, Steps: 15}
+	scrambled := 0
+	for seed := int64(0); seed < 50; seed++ {
+		data, moves := Scramble(seed, opts)
+		if data == "" || len(moves) == 0 {
+			continue
+		}
+		scrambled++
+		// without spikes nothing depends on where slimes came from so the moves always solve it
+		if !solves(t, data, moves) {
+			t.Fatalf("expected %s to solve\n%s", game.FormatMoves(moves), data)
+		}
+	}
+	if scrambled == 0 {
+		t.Fatalf("expected some levels to be scrambled")
+	}
+}
+
+func TestReverseErrors(t *testing.T) {
+	_, err := Reverse(context.Background(), Options{Width: 4, Height: 3, Switches: 1, Doors: 1})
+	if err == nil {
+		t.Fatalf("expected switches and doors to be rejected")
+	}
+}
+
+// solves reports whether moves win the level, the level may be won before the last move.
+func solves(t *testing.T, data string, moves []game.Direction) bool {
+	g := game.NewGame(nil)
+	if err := g.Parse(data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, dir := range moves {
+		g.Move(dir)
+		if g.Won() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package levels
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// packs holds one directory per pack with one level file per level.
+// Levels are ordered by file name so they are prefixed with their number.
+//
+//go:embed packs
+var packs embed.FS
+
+type Level struct {
+	Pack string
+	Name string
+	Data string
+}
+
+type Pack struct {
+	Name   string
+	Levels []Level
+}
+
+// Packs returns the built-in level packs sorted by name.
+func Packs() []Pack {
+	dirs, err := fs.ReadDir(packs, "packs")
+	if err != nil {
+		panic(err) // the embedded directory always exists
+	}
+
+	l := make([]Pack, 0, len(dirs))
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		l = append(l, readPack(dir.Name()))
+	}
+	sort.Slice(l, func(i, j int) bool {
+		return l[i].Name < l[j].Name
+	})
+	return l
+}
+
+func readPack(name string) Pack {
+	files, err := fs.ReadDir(packs, path.Join("packs", name))
+	if err != nil {
+		panic(err)
+	}
+
+	p := Pack{Name: name}
+	for _, file := range files {
+		if file.IsDir() || path.Ext(file.Name()) != ".txt" {
+			continue
+		}
+		data, err := fs.ReadFile(packs, path.Join("packs", name, file.Name()))
+		if err != nil {
+			panic(err)
+		}
+		p.Levels = append(p.Levels, Level{
+			Pack: name,
+			Name: strings.TrimSuffix(file.Name(), ".txt"),
+			Data: strings.TrimSpace(string(data)),
+		})
+	}
+	return p
+}
+
+// Get returns a built-in level by pack and level name.
+func Get(pack, name string) (Level, error) {
+	for _, p := range Packs() {
+		if p.Name != pack {
+			continue
+		}
+		for _, level := range p.Levels {
+			if level.Name == name {
+				return level, nil
+			}
+		}
+		return Level{}, fmt.Errorf("level %s not found in pack %s", name, pack)
+	}
+	return Level{}, fmt.Errorf("pack %s not found", pack)
+}
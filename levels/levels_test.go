@@ -0,0 +1,66 @@
+package levels
+
+import (
+	"slimesolver/game"
+	"testing"
+)
+
+// a known solution for every built-in level
+var solutions = map[string]string{
+	"classic/01-the-problem":  "ulllrrrrurrrrrrrrrrrrrr",
+	"tutorial/01-first-steps": "rrrr",
+	"tutorial/02-crates":      "rrrr",
+	"tutorial/03-switches":    "rrrdrrr",
+	"tutorial/04-split":       "urulll",
+	"tutorial/05-together":    "rrdrrurd",
+}
+
+func TestPacks(t *testing.T) {
+	packs := Packs()
+	if len(packs) == 0 {
+		t.Fatalf("expected built-in packs")
+	}
+
+	for _, pack := range packs {
+		for _, level := range pack.Levels {
+			t.Run(pack.Name+"/"+level.Name, func(t *testing.T) {
+				g := game.NewGame(nil)
+				if err := g.Parse(level.Data); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				solution, ok := solutions[pack.Name+"/"+level.Name]
+				if !ok {
+					t.Fatalf("no known solution")
+				}
+				moves, err := game.ParseMoves(solution)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				for _, dir := range moves {
+					g.Move(dir)
+				}
+				if !g.Won() {
+					t.Fatalf("expected the solution to win\n%s", g.String())
+				}
+			})
+		}
+	}
+}
+
+func TestGet(t *testing.T) {
+	level, err := Get("tutorial", "01-first-steps")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if level.Pack != "tutorial" || level.Data == "" {
+		t.Fatalf("unexpected level: %v", level)
+	}
+
+	if _, err := Get("tutorial", "missing"); err == nil {
+		t.Fatalf("expected error for a missing level")
+	}
+	if _, err := Get("missing", "01-first-steps"); err == nil {
+		t.Fatalf("expected error for a missing pack")
+	}
+}
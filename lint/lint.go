@@ -0,0 +1,418 @@
+// Package lint checks levels for mistakes before they are shipped in a pack.
+package lint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slimesolver/game"
+	"slimesolver/game/math"
+	"slimesolver/solver"
+	"sort"
+	"strings"
+)
+
+// Severity tells whether a problem makes a level broken or only looks like a mistake.
+type Severity string
+
+const (
+	Warning Severity = "warning"
+	Error   Severity = "error"
+)
+
+// Rule identifies a check.
+type Rule string
+
+const (
+	// RowWidth is a row that isn't as wide as the first one
+	RowWidth Rule = "row-width"
+	// InvalidToken is a character that isn't part of the level format
+	InvalidToken Rule = "invalid-token"
+	// InvalidRule is a rule line above the board that doesn't name a rule or sets it to a wrong value
+	InvalidRule Rule = "invalid-rule"
+	// UnpairedTeleporter is a teleporter pad whose digit isn't on exactly two pads
+	UnpairedTeleporter Rule = "unpaired-teleporter"
+	// NoSlimes is a level without slimes
+	NoSlimes Rule = "no-slimes"
+	// NoGoals is a level without goals, those can't be won
+	NoGoals Rule = "no-goals"
+	// Unreachable is a region of the board that no slime can walk to
+	Unreachable Rule = "unreachable"
+	// DoorWithoutSwitch is a door that never opens. Every switch opens every door,
+	// so this is a level with doors and no switches.
+	DoorWithoutSwitch Rule = "door-without-switch"
+	// LockWithoutKey is a locked door in a level without keys
+	LockWithoutKey Rule = "lock-without-key"
+	// SmallOnlySwitch is a switch only small slimes can reach,
+	// they only press it while growing
+	SmallOnlySwitch Rule = "small-only-switch"
+	// DeadBox is a box that starts where it can never be pushed into a pit or onto a switch
+	DeadBox Rule = "dead-box"
+	// AlreadyWon is a level that is won before the first move
+	AlreadyWon Rule = "already-won"
+	// Unsolvable is a level the solver proved can't be won
+	Unsolvable Rule = "unsolvable"
+	// Budget is a level the solver didn't solve within Options.MaxNodes or the context deadline
+	Budget Rule = "budget"
+)
+
+// DefaultMaxNodes bounds the solver when Options.MaxNodes isn't set.
+const DefaultMaxNodes = 200000
+
+type Options struct {
+	// MaxNodes bounds the search for a solution
+	MaxNodes int
+}
+
+// Problem is something wrong with a level at a tile, x and y count from 0.
+type Problem struct {
+	Rule     Rule     `json:"rule"`
+	Severity Severity `json:"severity"`
+	X        int      `json:"x"`
+	Y        int      `json:"y"`
+	Message  string   `json:"message"`
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%d,%d: %s %s: %s", p.X, p.Y, p.Severity, p.Rule, p.Message)
+}
+
+// HasErrors reports whether any of problems is an error.
+func HasErrors(problems []Problem) bool {
+	for _, p := range problems {
+		if p.Severity == Error {
+			return true
+		}
+	}
+	return false
+}
+
+// tokens that can appear in a level file
+var tokens = map[game.Token]bool{
+	game.WallToken:       true,
+	game.EmptyToken:      true,
+	game.PitToken:        true,
+	game.GoalToken:       true,
+	game.SlimeToken:      true,
+	game.SmallSlimeToken: true,
+	game.LargeSlimeToken: true,
+	game.HugeSlimeToken:  true,
+	game.BoxToken:        true,
+	game.LightBoxToken:   true,
+	game.HeavyBoxToken:   true,
+	game.SwitchToken:     true,
+	game.ClosedDoorToken: true,
+	game.SpikeUpToken:    true,
+	game.SpikeDownToken:  true,
+	game.KeyToken:        true,
+	game.LockedDoorToken: true,
+	game.IceToken:        true,
+	game.CrumblingToken:  true,
+
+	game.ConveyorUpToken:    true,
+	game.ConveyorDownToken:  true,
+	game.ConveyorLeftToken:  true,
+	game.ConveyorRightToken: true,
+
+	game.GateUpToken:    true,
+	game.GateDownToken:  true,
+	game.GateLeftToken:  true,
+	game.GateRightToken: true,
+}
+
+// Lint checks a level and returns its problems ordered by row and column.
+// Levels that can't be parsed are only checked for the format, and the solver
+// only runs when no other check found an error.
+func Lint(ctx context.Context, data string, opts Options) []Problem {
+	problems := format(data)
+	if HasErrors(problems) {
+		return sortProblems(problems)
+	}
+
+	g := game.NewGame(nil)
+	if err := g.Parse(data); err != nil {
+		// format found everything Parse complains about
+		return append(problems, Problem{Rule: InvalidToken, Severity: Error, Message: err.Error()})
+	}
+
+	problems = append(problems, board(g)...)
+	if !HasErrors(problems) {
+		problems = append(problems, solve(ctx, g, opts)...)
+	}
+	return sortProblems(problems)
+}
+
+func sortProblems(problems []Problem) []Problem {
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Y != problems[j].Y {
+			return problems[i].Y < problems[j].Y
+		}
+		return problems[i].X < problems[j].X
+	})
+	return problems
+}
+
+// format checks rule lines, rows and characters the way Game.Parse reads them.
+// Rows count from the first row of the board.
+func format(data string) []Problem {
+	_, data, err := game.ParseRules(data)
+	if err != nil {
+		return []Problem{{Rule: InvalidRule, Severity: Error, Message: err.Error()}}
+	}
+	data = strings.NewReplacer(" ", "", "\t", "", "\r\n", "\n").Replace(data)
+	lines := strings.Split(data, "\n")
+	width := len(lines[0])
+
+	var problems []Problem
+	pads := make(map[rune][]math.Vector2)
+	for y, line := range lines {
+		if len(line) != width {
+			problems = append(problems, Problem{
+				Rule: RowWidth, Severity: Error, X: min(len(line), width), Y: y,
+				Message: fmt.Sprintf("row is %d wide, the first row is %d", len(line), width),
+			})
+		}
+		for x, c := range line {
+			if game.IsTeleporterToken(game.Token(c)) {
+				pads[c] = append(pads[c], math.Vector2{X: x, Y: y})
+				continue
+			}
+			if !tokens[game.Token(c)] {
+				problems = append(problems, Problem{
+					Rule: InvalidToken, Severity: Error, X: x, Y: y,
+					Message: fmt.Sprintf("%q is not a tile or an actor", c),
+				})
+			}
+		}
+	}
+
+	for label, l := range pads {
+		if len(l) == 2 {
+			continue
+		}
+		for _, pos := range l {
+			problems = append(problems, Problem{
+				Rule: UnpairedTeleporter, Severity: Error, X: pos.X, Y: pos.Y,
+				Message: fmt.Sprintf("teleporter %c has %d pads, it needs 2", label, len(l)),
+			})
+		}
+	}
+	return problems
+}
+
+// board runs the checks that don't need a search.
+func board(g *game.Game) []Problem {
+	var problems []Problem
+	var slimes, bigSlimes, switches, doors, boxes, keys, locks []math.Vector2
+	for _, actor := range g.Actors() {
+		pos := actor.GetPosition()
+		if size := game.SlimeSize(actor.Token()); size > 0 {
+			slimes = append(slimes, pos)
+			if size > 1 {
+				bigSlimes = append(bigSlimes, pos)
+			}
+			continue
+		}
+		switch actor.Token() {
+		case game.SwitchToken:
+			switches = append(switches, pos)
+		case game.ClosedDoorToken, game.OpenDoorToken:
+			doors = append(doors, pos)
+		case game.BoxToken, game.LightBoxToken, game.HeavyBoxToken:
+			boxes = append(boxes, pos)
+		case game.KeyToken:
+			keys = append(keys, pos)
+		case game.LockedDoorToken:
+			locks = append(locks, pos)
+		}
+	}
+
+	goals, pits := 0, 0
+	for y := 0; y < g.Height(); y++ {
+		for x := 0; x < g.Width(); x++ {
+			switch {
+			case g.IsGoal(x, y):
+				goals++
+			case g.IsPit(x, y), g.IsCrumbling(x, y):
+				pits++
+			}
+		}
+	}
+
+	if len(slimes) == 0 {
+		problems = append(problems, Problem{Rule: NoSlimes, Severity: Error, Message: "there are no slimes"})
+	}
+	if goals == 0 {
+		problems = append(problems, Problem{Rule: NoGoals, Severity: Error, Message: "there are no goals, the level can't be won"})
+	} else if g.Won() {
+		problems = append(problems, Problem{Rule: AlreadyWon, Severity: Error, Message: "every goal has a slime on it before the first move"})
+	}
+
+	if len(switches) == 0 {
+		for _, pos := range doors {
+			problems = append(problems, Problem{
+				Rule: DoorWithoutSwitch, Severity: Error, X: pos.X, Y: pos.Y,
+				Message: "the door never opens, there are no switches",
+			})
+		}
+	}
+
+	if len(keys) == 0 {
+		for _, pos := range locks {
+			problems = append(problems, Problem{
+				Rule: LockWithoutKey, Severity: Error, X: pos.X, Y: pos.Y,
+				Message: "the locked door never opens, there are no keys",
+			})
+		}
+	}
+
+	// regions are what can be reached walking through everything but walls,
+	// doors might open and pits might be filled
+	regions := newRegions(g, g.IsWallOrEdge)
+	reached := make(map[int]bool)
+	for _, pos := range slimes {
+		reached[regions.at(pos)] = true
+	}
+	if len(slimes) > 0 {
+		for id, first := range regions.first {
+			if !reached[id] {
+				problems = append(problems, Problem{
+					Rule: Unreachable, Severity: Warning, X: first.X, Y: first.Y,
+					Message: fmt.Sprintf("no slime can reach these %d tiles", regions.size[id]),
+				})
+			}
+		}
+	}
+
+	// doors only open once a switch is pressed so they wall off the switches
+	closed := make(map[math.Vector2]bool)
+	for _, pos := range doors {
+		closed[pos] = true
+	}
+	rooms := newRegions(g, func(x, y int) bool {
+		return g.IsWallOrEdge(x, y) || closed[math.Vector2{X: x, Y: y}]
+	})
+	walkers, pressers := make(map[int]bool), make(map[int]bool)
+	for _, pos := range slimes {
+		walkers[rooms.at(pos)] = true
+	}
+	for _, pos := range append(bigSlimes, boxes...) {
+		pressers[rooms.at(pos)] = true
+	}
+	// unless the rules let small slimes press them
+	for _, pos := range switches {
+		if id := rooms.at(pos); walkers[id] && !pressers[id] && !g.Rules().SmallSlimesPress {
+			problems = append(problems, Problem{
+				Rule: SmallOnlySwitch, Severity: Warning, X: pos.X, Y: pos.Y,
+				Message: "only small slimes can reach the switch, they only press it while growing",
+			})
+		}
+	}
+
+	// without pits or switches boxes are only in the way
+	if pits > 0 || len(switches) > 0 {
+		analyzer := solver.NewAnalyzer(g)
+		for _, pos := range boxes {
+			if analyzer.DeadSquare(pos.X, pos.Y) {
+				problems = append(problems, Problem{
+					Rule: DeadBox, Severity: Warning, X: pos.X, Y: pos.Y,
+					Message: "the box can never be pushed into a pit or onto a switch",
+				})
+			}
+		}
+	}
+	return problems
+}
+
+// solve searches for a solution within the budget.
+func solve(ctx context.Context, g *game.Game, opts Options) []Problem {
+	maxNodes := opts.MaxNodes
+	if maxNodes <= 0 {
+		maxNodes = DefaultMaxNodes
+	}
+
+	_, err := solver.Solve(ctx, g, solver.Options{MaxNodes: maxNodes, Algorithm: solver.AStar, Prune: true})
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, solver.ErrNoSolution):
+		return []Problem{{Rule: Unsolvable, Severity: Error, Message: "the level can't be solved"}}
+	case errors.Is(err, solver.ErrLimit):
+		return []Problem{{Rule: Budget, Severity: Warning, Message: fmt.Sprintf("no solution found within %d nodes", maxNodes)}}
+	default:
+		return []Problem{{Rule: Budget, Severity: Warning, Message: fmt.Sprintf("no solution found: %v", err)}}
+	}
+}
+
+// regions numbers the groups of tiles that are connected without passing a blocked one,
+// or through a teleporter.
+type regions struct {
+	id [][]int
+	// first tile of each region in reading order, and how many tiles it has
+	first []math.Vector2
+	size  []int
+}
+
+func newRegions(g *game.Game, blocked func(x, y int) bool) *regions {
+	r := &regions{id: make([][]int, g.Height())}
+	for y := range r.id {
+		r.id[y] = make([]int, g.Width())
+		for x := range r.id[y] {
+			r.id[y][x] = -1
+		}
+	}
+
+	for y := 0; y < g.Height(); y++ {
+		for x := 0; x < g.Width(); x++ {
+			if r.id[y][x] != -1 || blocked(x, y) {
+				continue
+			}
+
+			id := len(r.first)
+			r.first = append(r.first, math.Vector2{X: x, Y: y})
+			r.size = append(r.size, 0)
+			r.id[y][x] = id
+			queue := []math.Vector2{{X: x, Y: y}}
+			for len(queue) > 0 {
+				pos := queue[0]
+				queue = queue[1:]
+				r.size[id]++
+
+				next := []math.Vector2{}
+				for _, dir := range solver.Directions {
+					next = append(next, neighbour(pos, dir))
+				}
+				// teleporters connect their pads
+				if partner, ok := g.Partner(pos); ok {
+					next = append(next, partner)
+				}
+				for _, n := range next {
+					if blocked(n.X, n.Y) || r.id[n.Y][n.X] != -1 {
+						continue
+					}
+					r.id[n.Y][n.X] = id
+					queue = append(queue, n)
+				}
+			}
+		}
+	}
+	return r
+}
+
+func (r *regions) at(pos math.Vector2) int {
+	return r.id[pos.Y][pos.X]
+}
+
+func neighbour(pos math.Vector2, dir game.Direction) math.Vector2 {
+	switch dir {
+	case game.Up:
+		pos.Y--
+	case game.Down:
+		pos.Y++
+	case game.Left:
+		pos.X--
+	case game.Right:
+		pos.X++
+	}
+	return pos
+}
@@ -0,0 +1,143 @@
+package lint
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	tt := []struct {
+		name     string
+		state    string
+		maxNodes int
+		want     []Problem
+	}{
+		{
+			name:  "clean",
+			state: `@B.O*`,
+		},
+		{
+			name:  "row width",
+			state: "@.*\n..",
+			want:  []Problem{{Rule: RowWidth, Severity: Error, X: 2, Y: 1}},
+		},
+		{
+			name:  "invalid token",
+			state: `@?*`,
+			want:  []Problem{{Rule: InvalidToken, Severity: Error, X: 1, Y: 0}},
+		},
+		{
+			name:  "unpaired teleporter",
+			state: `@1.2*2.1.1`,
+			want: []Problem{
+				{Rule: UnpairedTeleporter, Severity: Error, X: 1, Y: 0},
+				{Rule: UnpairedTeleporter, Severity: Error, X: 7, Y: 0},
+				{Rule: UnpairedTeleporter, Severity: Error, X: 9, Y: 0},
+			},
+		},
+		{
+			name: "teleporter reaches a room",
+			state: `
+				@1#1*`,
+		},
+		{
+			name:  "no slimes",
+			state: `..*`,
+			want:  []Problem{{Rule: NoSlimes, Severity: Error}},
+		},
+		{
+			name:  "no goals",
+			state: `@..`,
+			want:  []Problem{{Rule: NoGoals, Severity: Error}},
+		},
+		{
+			name: "unreachable",
+			state: `
+				@.*#..
+				...#..`,
+			want: []Problem{{Rule: Unreachable, Severity: Warning, X: 4, Y: 0}},
+		},
+		{
+			name:  "door without switch",
+			state: `@D*`,
+			want: []Problem{
+				{Rule: DoorWithoutSwitch, Severity: Error, X: 1, Y: 0},
+			},
+		},
+		{
+			name:  "lock without key",
+			state: `@L*`,
+			want: []Problem{
+				{Rule: LockWithoutKey, Severity: Error, X: 1, Y: 0},
+			},
+		},
+		{
+			name:  "key and lock",
+			state: `@kL*`,
+		},
+		{
+			name: "small only switch",
+			state: `
+				@D*
+				##.
+				ox.`,
+			want: []Problem{{Rule: SmallOnlySwitch, Severity: Warning, X: 1, Y: 2}},
+		},
+		{
+			name: "small slimes press switches",
+			state: `
+				; small-slimes-press = on
+				@D*
+				##.
+				ox.`,
+		},
+		{
+			name: "invalid rule",
+			state: `
+				; doors = off
+				@.*`,
+			want: []Problem{{Rule: InvalidRule, Severity: Error}},
+		},
+		{
+			name: "rows count from the board",
+			state: `
+				; pits-kill = off
+				@?*`,
+			want: []Problem{{Rule: InvalidToken, Severity: Error, X: 1, Y: 0}},
+		},
+		{
+			name: "dead box",
+			state: `
+				B..
+				.@O
+				..*`,
+			want: []Problem{{Rule: DeadBox, Severity: Warning}},
+		},
+		{
+			name:  "unsolvable",
+			state: `@.O*`,
+			want:  []Problem{{Rule: Unsolvable, Severity: Error}},
+		},
+		{
+			name:     "budget",
+			state:    `@........*`,
+			maxNodes: 2,
+			want:     []Problem{{Rule: Budget, Severity: Warning}},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			problems := Lint(context.Background(), strings.TrimSpace(tc.state), Options{MaxNodes: tc.maxNodes})
+			// messages are for people, the tests only compare what was found where
+			for i := range problems {
+				problems[i].Message = ""
+			}
+			if !reflect.DeepEqual(problems, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, problems)
+			}
+		})
+	}
+}
@@ -1,34 +1,93 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"log/slog"
 	"os"
 	"slimesolver/game"
+	"slimesolver/render"
+	"slimesolver/solver"
 	"strings"
+	"time"
 )
 
+const defaultLevel = "level.txt"
+
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "render":
+			runRender(os.Args[2:])
+			return
+		case "trace":
+			runTrace(os.Args[2:])
+			return
+		case "solve":
+			runSolve(os.Args[2:])
+			return
+		case "minimize":
+			runMinimize(os.Args[2:])
+			return
+		case "stats":
+			runStats(os.Args[2:])
+			return
+		case "lint":
+			runLint(os.Args[2:])
+			return
+		case "generate":
+			runGenerate(os.Args[2:])
+			return
+		case "serve":
+			runServe(os.Args[2:])
+			return
+		}
+	}
+
+	runGame(loadLevel(defaultLevel))
+}
+
+// newLogger returns a logger writing to stderr at the level named by SLIME_LOG
+// (debug or trace), or nil when logging is off.
+func newLogger() *slog.Logger {
+	var level slog.Level
+	switch strings.ToLower(os.Getenv("SLIME_LOG")) {
+	case "debug":
+		level = slog.LevelDebug
+	case "trace":
+		level = game.LevelTrace
+	default:
+		return nil
+	}
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+}
+
+func loadLevel(path string) string {
 	// load the level data
-	levelData, err := os.ReadFile("level.txt")
+	levelData, err := os.ReadFile(path)
 	if err != nil {
 		log.Fatal(err)
 	}
-
-	runGame(string(levelData))
+	// editors like to end files with a newline
+	return strings.TrimSpace(string(levelData))
 }
 
-const helpText = `w|up, s|down, a|left, d|right, q|quit, r|restart`
+const helpText = `w|up, s|down, a|left, d|right, u|undo, h|hint, q|quit, r|restart`
+
+// hintBudget is how long the hint command searches before guessing
+const hintBudget = 3 * time.Second
 
 func runGame(levelData string) {
-	g := game.NewGame(false)
+	g := game.NewGame(newLogger())
 	err := g.Parse(levelData)
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	r := render.NewANSI(render.ColorEnabled(os.Stdout))
 	for {
-		fmt.Println(g.String())
+		fmt.Println(r.Render(g))
 		fmt.Printf("> ")
 
 		var input string
@@ -40,6 +99,7 @@ func runGame(levelData string) {
 
 		dir := game.Zero
 		restart := false
+		undo := false
 		switch input {
 		case "w", "up":
 			dir = game.Up
@@ -53,6 +113,11 @@ func runGame(levelData string) {
 			os.Exit(0)
 		case "r", "restart", "reset":
 			restart = true
+		case "u", "undo":
+			undo = true
+		case "h", "hint":
+			printHint(g)
+			continue
 		}
 
 		if restart {
@@ -63,10 +128,46 @@ func runGame(levelData string) {
 			continue
 		}
 
+		if undo {
+			if !g.Undo() {
+				fmt.Println("nothing to undo")
+			}
+			continue
+		}
+
 		if dir != game.Zero {
-			g.Move(dir)
+			g.Play(dir)
+			if g.Won() {
+				fmt.Println(r.Render(g))
+				fmt.Printf("solved in %d moves: %s\n", g.Turn(), game.FormatMoves(g.History()))
+				return
+			}
 		} else {
 			fmt.Println(helpText)
 		}
 	}
 }
+
+func printHint(g *game.Game) {
+	hint := solver.Hint(context.Background(), g, solver.Budget{Time: hintBudget})
+	switch hint.Confidence {
+	case solver.Proven:
+		fmt.Printf("try %s, %d moves left\n", hint.Direction, hint.MovesLeft)
+	case solver.Guess:
+		if hint.Direction == game.Zero {
+			fmt.Println("no idea, every move looks bad")
+			return
+		}
+		fmt.Printf("maybe %s, ran out of time looking for a solution\n", hint.Direction)
+	case solver.Unwinnable:
+		if hint.Undo == solver.UndoUnknown {
+			fmt.Println("this can't be won anymore, ran out of time looking for how many moves to undo")
+			return
+		}
+		if hint.Undo > 0 {
+			fmt.Printf("this can't be won anymore, undo %d moves\n", hint.Undo)
+			return
+		}
+		fmt.Println("this can't be won anymore, restart")
+	}
+}
@@ -0,0 +1,182 @@
+package render
+
+import (
+	"os"
+	"slimesolver/game"
+	"slimesolver/game/math"
+	"strings"
+)
+
+const (
+	reset = "\x1b[0m"
+
+	// spikes that are down will come up at the end of the next move
+	upcomingSpikeBackground = "43"
+)
+
+// foreground colors for the token drawn on top of a tile
+var foreground = map[game.Token]string{
+	game.WallToken:         "90",
+	game.EmptyToken:        "2",
+	game.PitToken:          "1;34",
+	game.SlimeToken:        "1;32",
+	game.SmallSlimeToken:   "96",
+	game.LargeSlimeToken:   "1;92",
+	game.HugeSlimeToken:    "1;4;92",
+	game.BoxToken:          "33",
+	game.LightBoxToken:     "93",
+	game.HeavyBoxToken:     "1;33",
+	game.SwitchToken:       "35",
+	game.ClosedDoorToken:   "1;31",
+	game.OpenDoorToken:     "31",
+	game.SpikeUpToken:      "1;91",
+	game.SpikeDownToken:    "91",
+	game.PusherToken:       "36",
+	game.PusherActiveToken: "1;36",
+	game.GoalToken:         "1;93",
+	game.KeyToken:          "93",
+	game.LockedDoorToken:   "1;33",
+	game.IceToken:          "97",
+	game.CrumblingToken:    "33",
+
+	game.ConveyorUpToken:    "1;37",
+	game.ConveyorDownToken:  "1;37",
+	game.ConveyorLeftToken:  "1;37",
+	game.ConveyorRightToken: "1;37",
+
+	game.GateUpToken:    "1;95",
+	game.GateDownToken:  "1;95",
+	game.GateLeftToken:  "1;95",
+	game.GateRightToken: "1;95",
+}
+
+// background colors for actors that are covered by another actor
+var background = map[game.Token]string{
+	game.SwitchToken:    "45",
+	game.OpenDoorToken:  "41",
+	game.SpikeUpToken:   "101",
+	game.SpikeDownToken: upcomingSpikeBackground,
+	game.GoalToken:      "42",
+	game.KeyToken:       "103",
+	game.IceToken:       "46",
+	game.CrumblingToken: "43",
+
+	game.ConveyorUpToken:    "100",
+	game.ConveyorDownToken:  "100",
+	game.ConveyorLeftToken:  "100",
+	game.ConveyorRightToken: "100",
+
+	game.GateUpToken:    "105",
+	game.GateDownToken:  "105",
+	game.GateLeftToken:  "105",
+	game.GateRightToken: "105",
+}
+
+// teleporter pads are colored alike whatever their digit
+const (
+	teleporterForeground = "1;94"
+	teleporterBackground = "44"
+)
+
+func foregroundOf(token game.Token) string {
+	if game.IsTeleporterToken(token) {
+		return teleporterForeground
+	}
+	return foreground[token]
+}
+
+func backgroundOf(token game.Token) string {
+	if game.IsTeleporterToken(token) {
+		return teleporterBackground
+	}
+	return background[token]
+}
+
+// ANSI renders the board as text, coloring every token with ANSI escape codes.
+// Actors standing on a switch, spike, open door or goal get the background color
+// of the tile underneath them so stacked actors can be told apart.
+type ANSI struct {
+	// Color enables the escape codes, without it the output matches Game.String
+	Color bool
+}
+
+func NewANSI(color bool) *ANSI {
+	return &ANSI{
+		Color: color,
+	}
+}
+
+// ColorEnabled reports whether colored output should be written to f.
+// Color is disabled when NO_COLOR is set or f is not a terminal.
+func ColorEnabled(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+func (r *ANSI) Render(g *game.Game) string {
+	if !r.Color {
+		return g.String()
+	}
+
+	var sb strings.Builder
+	for y := 0; y < g.Height(); y++ {
+		for x := 0; x < g.Width(); x++ {
+			writeCell(&sb, g, x, y)
+		}
+		sb.WriteRune('\n')
+	}
+	return sb.String()
+}
+
+func writeCell(sb *strings.Builder, g *game.Game, x, y int) {
+	token := g.GetTokenAt(x, y)
+	bg := ""
+
+	actors := g.GetActors(math.Vector2{X: x, Y: y})
+	if len(actors) > 0 {
+		// goals are part of the board so they are only covered when nothing else is
+		bg = backgroundOf(token)
+		token = game.PriorityToken(actors)
+		if covered := coveredBackground(g, actors, token); covered != "" {
+			bg = covered
+		}
+	}
+
+	// a lone spike that is about to come up is highlighted as well
+	if token == game.SpikeDownToken && g.Rules().SpikesAlternate {
+		bg = upcomingSpikeBackground
+	}
+
+	sb.WriteString("\x1b[")
+	sb.WriteString(foregroundOf(token))
+	if bg != "" {
+		sb.WriteString(";")
+		sb.WriteString(bg)
+	}
+	sb.WriteString("m")
+	sb.WriteRune(rune(token))
+	sb.WriteString(reset)
+}
+
+// coveredBackground returns the background color of the first actor hidden
+// under the top token, or an empty string if nothing interesting is covered.
+// Lowered spikes only come up when the rules make them alternate.
+func coveredBackground(g *game.Game, actors []game.Actor, top game.Token) string {
+	for _, actor := range actors {
+		token := actor.Token()
+		if token == top || token == game.SpikeDownToken && !g.Rules().SpikesAlternate {
+			continue
+		}
+		if bg := backgroundOf(token); bg != "" {
+			return bg
+		}
+	}
+	return ""
+}
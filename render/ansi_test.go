@@ -0,0 +1,160 @@
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"slimesolver/game"
+	"strings"
+	"testing"
+)
+
+func newGame(t *testing.T, state string, inputs ...game.Direction) *game.Game {
+	t.Helper()
+	g := game.NewGame(nil)
+	if err := g.Parse(state); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, dir := range inputs {
+		g.Move(dir)
+	}
+	return g
+}
+
+func TestANSIPlain(t *testing.T) {
+	g := newGame(t, "#@oOB\n#x-D.")
+
+	result := NewANSI(false).Render(g)
+	if result != g.String() {
+		t.Fatalf("expected:\n%s\ngot\n%s", g.String(), result)
+	}
+}
+
+func TestANSIColor(t *testing.T) {
+	tt := []struct {
+		name   string
+		state  string
+		inputs []game.Direction
+		want   []string
+	}{
+		{
+			name:  "slimes and pits differ",
+			state: `@oO`,
+			want: []string{
+				"\x1b[1;32m@" + reset,
+				"\x1b[96mo" + reset,
+				"\x1b[1;34mO" + reset,
+			},
+		},
+		{
+			name:   "box on switch",
+			state:  `@BxD`,
+			inputs: []game.Direction{game.Right},
+			want: []string{
+				"\x1b[33;45mB" + reset,
+				"\x1b[31m_" + reset,
+			},
+		},
+		{
+			name:  "slime sizes differ",
+			state: "; slime-size = 4\no@&%",
+			want: []string{
+				"\x1b[1;92m&" + reset,
+				"\x1b[1;4;92m%" + reset,
+			},
+		},
+		{
+			name:   "slime on spike",
+			state:  `@.-`,
+			inputs: []game.Direction{game.Right, game.Right},
+			want: []string{
+				"\x1b[1;32;43m@" + reset,
+			},
+		},
+		{
+			name:   "slime on goal",
+			state:  `@*`,
+			inputs: []game.Direction{game.Right},
+			want: []string{
+				"\x1b[1;32;42m@" + reset,
+			},
+		},
+		{
+			name:   "slime on ice",
+			state:  `@~~`,
+			inputs: []game.Direction{game.Right},
+			want: []string{
+				"\x1b[97m~" + reset,
+				"\x1b[1;32;46m@" + reset,
+			},
+		},
+		{
+			name:   "slime on conveyor",
+			state:  `@>#`,
+			inputs: []game.Direction{game.Right},
+			want: []string{
+				"\x1b[1;32;100m@" + reset,
+			},
+		},
+		{
+			name:   "slime on gate",
+			state:  `@r`,
+			inputs: []game.Direction{game.Right},
+			want: []string{
+				"\x1b[1;32;105m@" + reset,
+			},
+		},
+		{
+			name:   "slime on teleporter",
+			state:  `@1.1`,
+			inputs: []game.Direction{game.Right},
+			want: []string{
+				"\x1b[1;94m1" + reset,
+				"\x1b[1;32;44m@" + reset,
+			},
+		},
+		{
+			name:  "upcoming spike",
+			state: `-^`,
+			want: []string{
+				"\x1b[91;43m-" + reset,
+				"\x1b[1;91m^" + reset,
+			},
+		},
+		{
+			name:  "spike that stays down",
+			state: "; spikes-alternate = off\n-^",
+			want: []string{
+				"\x1b[91m-" + reset,
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			g := newGame(t, tc.state, tc.inputs...)
+			result := NewANSI(true).Render(g)
+			for _, want := range tc.want {
+				if !strings.Contains(result, want) {
+					t.Fatalf("expected %q in %q", want, result)
+				}
+			}
+		})
+	}
+}
+
+func TestColorEnabled(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "out.txt"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer f.Close()
+
+	if ColorEnabled(f) {
+		t.Fatalf("expected color to be disabled for a regular file")
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	if ColorEnabled(os.Stdout) {
+		t.Fatalf("expected color to be disabled when NO_COLOR is set")
+	}
+}
@@ -0,0 +1,230 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"io"
+	gomath "math"
+	"slimesolver/game"
+	"sort"
+)
+
+// DefaultTileSize is the width and height in pixels of a tile when no size is given.
+const DefaultTileSize = 32
+
+// DefaultDelay is the time a GIF frame is shown after each move in 100ths of a second.
+const DefaultDelay = 40
+
+// sprite is an actor drawn at a position measured in tiles.
+// Positions are fractional while tweening between two moves.
+type sprite struct {
+	token game.Token
+	x, y  float64
+}
+
+// frame is everything needed to draw the board once.
+type frame struct {
+	board   [][]game.Token
+	sprites []sprite
+}
+
+func snapshot(g *game.Game) frame {
+	board := make([][]game.Token, g.Height())
+	for y := range board {
+		board[y] = make([]game.Token, g.Width())
+		for x := range board[y] {
+			board[y][x] = g.GetTokenAt(x, y)
+		}
+	}
+
+	sprites := make([]sprite, 0)
+	for _, actor := range g.Actors() {
+		pos := actor.GetPosition()
+		sprites = append(sprites, sprite{actor.Token(), float64(pos.X), float64(pos.Y)})
+	}
+	sortSprites(sprites)
+
+	return frame{board, sprites}
+}
+
+func sortSprites(sprites []sprite) {
+	sort.SliceStable(sprites, func(i, j int) bool {
+		return layer(sprites[i].token) < layer(sprites[j].token)
+	})
+}
+
+func tileSizeOrDefault(tileSize int) int {
+	if tileSize <= 0 {
+		return DefaultTileSize
+	}
+	return tileSize
+}
+
+func (f frame) bounds(tileSize int) image.Rectangle {
+	width := 0
+	if len(f.board) > 0 {
+		width = len(f.board[0])
+	}
+	return image.Rect(0, 0, width*tileSize, len(f.board)*tileSize)
+}
+
+func (f frame) draw(img draw.Image, tileSize int) {
+	for y, row := range f.board {
+		for x, token := range row {
+			drawShapes(img, art(token), float64(x), float64(y), tileSize)
+		}
+	}
+
+	for _, s := range f.sprites {
+		drawShapes(img, art(s.token), s.x, s.y, tileSize)
+	}
+}
+
+func drawShapes(img draw.Image, shapes []shape, ox, oy float64, tileSize int) {
+	ts := float64(tileSize)
+	for _, s := range shapes {
+		switch s.kind {
+		case rectShape:
+			r := image.Rect(
+				int(gomath.Round((ox+s.x)*ts)),
+				int(gomath.Round((oy+s.y)*ts)),
+				int(gomath.Round((ox+s.x+s.w)*ts)),
+				int(gomath.Round((oy+s.y+s.h)*ts)),
+			)
+			draw.Draw(img, r, image.NewUniform(s.color), image.Point{}, draw.Src)
+		case circleShape:
+			cx := (ox + s.x) * ts
+			cy := (oy + s.y) * ts
+			r := s.w * ts
+			for py := int(cy - r); py <= int(cy+r); py++ {
+				for px := int(cx - r); px <= int(cx+r); px++ {
+					dx := float64(px) + 0.5 - cx
+					dy := float64(py) + 0.5 - cy
+					if dx*dx+dy*dy <= r*r {
+						img.Set(px, py, s.color)
+					}
+				}
+			}
+		}
+	}
+}
+
+// Image draws the board using the built-in tile art.
+func Image(g *game.Game, tileSize int) *image.RGBA {
+	tileSize = tileSizeOrDefault(tileSize)
+	f := snapshot(g)
+	img := image.NewRGBA(f.bounds(tileSize))
+	f.draw(img, tileSize)
+	return img
+}
+
+// PNG writes the board as a PNG image.
+func PNG(w io.Writer, g *game.Game, tileSize int) error {
+	return png.Encode(w, Image(g, tileSize))
+}
+
+// SVG writes the board as an SVG image using the same tile art as PNG.
+func SVG(w io.Writer, g *game.Game, tileSize int) error {
+	tileSize = tileSizeOrDefault(tileSize)
+	f := snapshot(g)
+	b := f.bounds(tileSize)
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		b.Dx(), b.Dy(), b.Dx(), b.Dy())
+	for y, row := range f.board {
+		for x, token := range row {
+			writeSVGShapes(bw, art(token), float64(x), float64(y), tileSize)
+		}
+	}
+	for _, s := range f.sprites {
+		writeSVGShapes(bw, art(s.token), s.x, s.y, tileSize)
+	}
+	fmt.Fprintln(bw, "</svg>")
+	return bw.Flush()
+}
+
+func writeSVGShapes(w io.Writer, shapes []shape, ox, oy float64, tileSize int) {
+	ts := float64(tileSize)
+	for _, s := range shapes {
+		switch s.kind {
+		case rectShape:
+			fmt.Fprintf(w, `<rect x="%g" y="%g" width="%g" height="%g" fill="%s"/>`+"\n",
+				(ox+s.x)*ts, (oy+s.y)*ts, s.w*ts, s.h*ts, hexColor(s.color))
+		case circleShape:
+			fmt.Fprintf(w, `<circle cx="%g" cy="%g" r="%g" fill="%s"/>`+"\n",
+				(ox+s.x)*ts, (oy+s.y)*ts, s.w*ts, hexColor(s.color))
+		}
+	}
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+type GIFOptions struct {
+	// TileSize is the size of a tile in pixels, DefaultTileSize is used when 0
+	TileSize int
+	// Delay is how long the board is shown after each move in 100ths of a second, DefaultDelay is used when 0
+	Delay int
+	// Tween is the number of in-between frames drawn while actors move, 0 disables tweening
+	Tween int
+}
+
+// GIF writes an animated replay of moves.
+// The moves are applied to g through Move so g is left in the final state.
+func GIF(w io.Writer, g *game.Game, moves []game.Direction, opts GIFOptions) error {
+	tileSize := tileSizeOrDefault(opts.TileSize)
+	delay := opts.Delay
+	if delay <= 0 {
+		delay = DefaultDelay
+	}
+	pal := palette()
+
+	anim := &gif.GIF{}
+	addFrame := func(f frame, delay int) {
+		img := image.NewPaletted(f.bounds(tileSize), pal)
+		f.draw(img, tileSize)
+		anim.Image = append(anim.Image, img)
+		anim.Delay = append(anim.Delay, delay)
+	}
+
+	addFrame(snapshot(g), delay)
+	for _, dir := range moves {
+		before := snapshot(g)
+		actors := g.Actors()
+		start := make([]sprite, len(actors))
+		for i, actor := range actors {
+			pos := actor.GetPosition()
+			start[i] = sprite{actor.Token(), float64(pos.X), float64(pos.Y)}
+		}
+
+		g.Move(dir)
+
+		// actors removed this turn still know where they ended up
+		tweenDelay := delay / (opts.Tween + 1)
+		for i := 1; i <= opts.Tween; i++ {
+			t := float64(i) / float64(opts.Tween+1)
+			sprites := make([]sprite, len(actors))
+			for j, actor := range actors {
+				pos := actor.GetPosition()
+				sprites[j] = sprite{
+					token: start[j].token,
+					x:     start[j].x + (float64(pos.X)-start[j].x)*t,
+					y:     start[j].y + (float64(pos.Y)-start[j].y)*t,
+				}
+			}
+			sortSprites(sprites)
+			addFrame(frame{before.board, sprites}, tweenDelay)
+		}
+
+		addFrame(snapshot(g), delay)
+	}
+
+	return gif.EncodeAll(w, anim)
+}
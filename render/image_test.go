@@ -0,0 +1,105 @@
+package render
+
+import (
+	"bytes"
+	"image/gif"
+	"image/png"
+	"slimesolver/game"
+	"strings"
+	"testing"
+)
+
+func TestPNG(t *testing.T) {
+	g := newGame(t, "#@O")
+
+	var buf bytes.Buffer
+	if err := PNG(&buf, g, 10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if img.Bounds().Dx() != 30 || img.Bounds().Dy() != 10 {
+		t.Fatalf("expected 30x10 image, got %v", img.Bounds())
+	}
+
+	// center of the slime body
+	r, gr, b, _ := img.At(15, 7).RGBA()
+	wr, wg, wb, _ := slimeColor.RGBA()
+	if r != wr || gr != wg || b != wb {
+		t.Fatalf("expected slime color at the center of the slime tile")
+	}
+
+	// corner of the wall tile
+	r, gr, b, _ = img.At(0, 0).RGBA()
+	wr, wg, wb, _ = wallColor.RGBA()
+	if r != wr || gr != wg || b != wb {
+		t.Fatalf("expected wall color at the corner of the wall tile")
+	}
+}
+
+func TestSVG(t *testing.T) {
+	g := newGame(t, "@B.")
+
+	var buf bytes.Buffer
+	if err := SVG(&buf, g, 16); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result := buf.String()
+	if !strings.HasPrefix(result, `<svg xmlns="http://www.w3.org/2000/svg" width="48" height="16"`) {
+		t.Fatalf("unexpected svg header: %s", result)
+	}
+	if !strings.Contains(result, hexColor(slimeColor)) || !strings.Contains(result, hexColor(boxColor)) {
+		t.Fatalf("expected slime and box art in svg")
+	}
+	if !strings.HasSuffix(result, "</svg>\n") {
+		t.Fatalf("expected svg to be closed")
+	}
+}
+
+func TestGIF(t *testing.T) {
+	tt := []struct {
+		name   string
+		tween  int
+		frames int
+	}{
+		{
+			name:   "no tween",
+			tween:  0,
+			frames: 3,
+		},
+		{
+			name:   "tween",
+			tween:  3,
+			frames: 9,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			g := newGame(t, "@B..")
+
+			var buf bytes.Buffer
+			opts := GIFOptions{TileSize: 8, Tween: tc.tween}
+			if err := GIF(&buf, g, []game.Direction{game.Right, game.Right}, opts); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			anim, err := gif.DecodeAll(&buf)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(anim.Image) != tc.frames {
+				t.Fatalf("expected %d frames, got %d", tc.frames, len(anim.Image))
+			}
+
+			// the replay is applied to the game
+			if g.String() != "..@B\n" {
+				t.Fatalf("expected game to be in the final state, got\n%s", g.String())
+			}
+		})
+	}
+}
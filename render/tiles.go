@@ -0,0 +1,297 @@
+package render
+
+import (
+	"image/color"
+	"slimesolver/game"
+)
+
+type shapeKind int
+
+const (
+	rectShape shapeKind = iota
+	circleShape
+)
+
+// shape is a primitive of the built-in tile art.
+// Coordinates are in tile units so the same art can be drawn at any tile size.
+// Rects use x, y, w, h and circles are centered on x, y with radius w.
+type shape struct {
+	kind       shapeKind
+	x, y, w, h float64
+	color      color.RGBA
+}
+
+func rect(x, y, w, h float64, c color.RGBA) shape {
+	return shape{kind: rectShape, x: x, y: y, w: w, h: h, color: c}
+}
+
+func circle(x, y, r float64, c color.RGBA) shape {
+	return shape{kind: circleShape, x: x, y: y, w: r, color: c}
+}
+
+var (
+	floorColor      = color.RGBA{0x2b, 0x2b, 0x36, 0xff}
+	wallColor       = color.RGBA{0x6b, 0x6b, 0x7a, 0xff}
+	wallShadeColor  = color.RGBA{0x55, 0x55, 0x63, 0xff}
+	pitColor        = color.RGBA{0x0b, 0x0b, 0x14, 0xff}
+	slimeColor      = color.RGBA{0x3c, 0xc8, 0x5a, 0xff}
+	smallSlimeColor = color.RGBA{0x8c, 0xe6, 0xc8, 0xff}
+	largeSlimeColor = color.RGBA{0x28, 0xa0, 0x46, 0xff}
+	hugeSlimeColor  = color.RGBA{0x19, 0x78, 0x32, 0xff}
+	eyeColor        = color.RGBA{0xf5, 0xf5, 0xf5, 0xff}
+	boxColor        = color.RGBA{0xa0, 0x64, 0x2d, 0xff}
+	boxLidColor     = color.RGBA{0xb9, 0x7a, 0x3c, 0xff}
+	heavyBandColor  = color.RGBA{0x5a, 0x5a, 0x64, 0xff}
+	switchColor     = color.RGBA{0xb4, 0x3c, 0xb4, 0xff}
+	doorColor       = color.RGBA{0xb4, 0x32, 0x32, 0xff}
+	doorBarColor    = color.RGBA{0x78, 0x1e, 0x1e, 0xff}
+	spikeColor      = color.RGBA{0xd0, 0xd0, 0xd0, 0xff}
+	spikeHoleColor  = color.RGBA{0x70, 0x70, 0x70, 0xff}
+	pusherColor     = color.RGBA{0x3c, 0xb4, 0xc8, 0xff}
+	goalColor       = color.RGBA{0xf0, 0xd2, 0x3c, 0xff}
+	keyColor        = color.RGBA{0xe6, 0xb4, 0x28, 0xff}
+	lockColor       = color.RGBA{0x8c, 0x6e, 0x46, 0xff}
+	iceColor        = color.RGBA{0xa8, 0xd8, 0xf0, 0xff}
+	iceShineColor   = color.RGBA{0xe6, 0xf6, 0xff, 0xff}
+	beltColor       = color.RGBA{0x4a, 0x4a, 0x58, 0xff}
+	beltArrowColor  = color.RGBA{0xd2, 0xa0, 0x3c, 0xff}
+	gateColor       = color.RGBA{0xdc, 0x78, 0xdc, 0xff}
+	crackColor      = color.RGBA{0x14, 0x14, 0x1e, 0xff}
+
+	// teleporter pads are colored by their digit so the two pads of a pair match
+	teleporterColors = []color.RGBA{
+		{0x3c, 0x78, 0xf0, 0xff},
+		{0xf0, 0x78, 0x3c, 0xff},
+		{0x3c, 0xd2, 0xd2, 0xff},
+		{0xd2, 0x3c, 0x8c, 0xff},
+		{0x96, 0xd2, 0x3c, 0xff},
+	}
+)
+
+// tileArt is drawn for every token, board tokens first and actors on top.
+var tileArt = map[game.Token][]shape{
+	game.EmptyToken: {
+		rect(0, 0, 1, 1, floorColor),
+	},
+	game.WallToken: {
+		rect(0, 0, 1, 1, wallColor),
+		rect(0.1, 0.1, 0.8, 0.8, wallShadeColor),
+	},
+	game.PitToken: {
+		rect(0, 0, 1, 1, floorColor),
+		rect(0.1, 0.1, 0.8, 0.8, pitColor),
+	},
+	game.SlimeToken: {
+		circle(0.5, 0.55, 0.4, slimeColor),
+		circle(0.38, 0.45, 0.07, eyeColor),
+		circle(0.62, 0.45, 0.07, eyeColor),
+	},
+	game.SmallSlimeToken: {
+		circle(0.5, 0.6, 0.25, smallSlimeColor),
+		circle(0.42, 0.55, 0.05, eyeColor),
+		circle(0.58, 0.55, 0.05, eyeColor),
+	},
+	game.LargeSlimeToken: {
+		circle(0.5, 0.52, 0.45, largeSlimeColor),
+		circle(0.36, 0.42, 0.08, eyeColor),
+		circle(0.64, 0.42, 0.08, eyeColor),
+	},
+	game.HugeSlimeToken: {
+		rect(0.02, 0.5, 0.96, 0.48, hugeSlimeColor),
+		circle(0.5, 0.5, 0.48, hugeSlimeColor),
+		circle(0.34, 0.4, 0.09, eyeColor),
+		circle(0.66, 0.4, 0.09, eyeColor),
+	},
+	game.BoxToken: {
+		rect(0.12, 0.12, 0.76, 0.76, boxColor),
+		rect(0.22, 0.22, 0.56, 0.56, boxLidColor),
+	},
+	game.LightBoxToken: {
+		rect(0.22, 0.22, 0.56, 0.56, boxLidColor),
+		rect(0.32, 0.32, 0.36, 0.36, boxColor),
+	},
+	game.HeavyBoxToken: {
+		rect(0.06, 0.06, 0.88, 0.88, boxColor),
+		rect(0.16, 0.16, 0.68, 0.68, boxLidColor),
+		rect(0.06, 0.3, 0.88, 0.1, heavyBandColor),
+		rect(0.06, 0.6, 0.88, 0.1, heavyBandColor),
+	},
+	game.SwitchToken: {
+		circle(0.5, 0.5, 0.3, switchColor),
+		circle(0.5, 0.5, 0.18, floorColor),
+	},
+	game.ClosedDoorToken: {
+		rect(0.05, 0.05, 0.9, 0.9, doorColor),
+		rect(0.45, 0.05, 0.1, 0.9, doorBarColor),
+	},
+	game.OpenDoorToken: {
+		rect(0.05, 0.05, 0.12, 0.9, doorColor),
+		rect(0.83, 0.05, 0.12, 0.9, doorColor),
+	},
+	game.SpikeUpToken: {
+		rect(0.18, 0.2, 0.12, 0.6, spikeColor),
+		rect(0.44, 0.2, 0.12, 0.6, spikeColor),
+		rect(0.7, 0.2, 0.12, 0.6, spikeColor),
+	},
+	game.SpikeDownToken: {
+		circle(0.24, 0.5, 0.06, spikeHoleColor),
+		circle(0.5, 0.5, 0.06, spikeHoleColor),
+		circle(0.76, 0.5, 0.06, spikeHoleColor),
+	},
+	game.PusherToken: {
+		rect(0.1, 0.1, 0.8, 0.8, pusherColor),
+	},
+	game.PusherActiveToken: {
+		rect(0.1, 0.1, 0.8, 0.8, pusherColor),
+		rect(0.3, 0.3, 0.4, 0.4, eyeColor),
+	},
+	game.GoalToken: {
+		rect(0, 0, 1, 1, floorColor),
+		circle(0.5, 0.5, 0.42, goalColor),
+		circle(0.5, 0.5, 0.32, floorColor),
+	},
+	game.KeyToken: {
+		circle(0.32, 0.5, 0.15, keyColor),
+		circle(0.32, 0.5, 0.07, floorColor),
+		rect(0.45, 0.46, 0.35, 0.08, keyColor),
+		rect(0.68, 0.54, 0.06, 0.12, keyColor),
+	},
+	game.LockedDoorToken: {
+		rect(0.05, 0.05, 0.9, 0.9, lockColor),
+		circle(0.5, 0.42, 0.1, pitColor),
+		rect(0.46, 0.45, 0.08, 0.2, pitColor),
+	},
+	game.IceToken: {
+		rect(0, 0, 1, 1, iceColor),
+		rect(0.2, 0.25, 0.3, 0.08, iceShineColor),
+		rect(0.55, 0.65, 0.25, 0.08, iceShineColor),
+	},
+	game.CrumblingToken: {
+		rect(0, 0, 1, 1, floorColor),
+		rect(0.15, 0.3, 0.35, 0.06, crackColor),
+		rect(0.45, 0.3, 0.06, 0.3, crackColor),
+		rect(0.45, 0.6, 0.4, 0.06, crackColor),
+	},
+	// conveyors are a belt with an arrow head and shaft pointing where they carry actors
+	game.ConveyorUpToken: {
+		rect(0, 0, 1, 1, beltColor),
+		rect(0.25, 0.2, 0.5, 0.15, beltArrowColor),
+		rect(0.42, 0.35, 0.16, 0.45, beltArrowColor),
+	},
+	game.ConveyorDownToken: {
+		rect(0, 0, 1, 1, beltColor),
+		rect(0.25, 0.65, 0.5, 0.15, beltArrowColor),
+		rect(0.42, 0.2, 0.16, 0.45, beltArrowColor),
+	},
+	game.ConveyorLeftToken: {
+		rect(0, 0, 1, 1, beltColor),
+		rect(0.2, 0.25, 0.15, 0.5, beltArrowColor),
+		rect(0.35, 0.42, 0.45, 0.16, beltArrowColor),
+	},
+	game.ConveyorRightToken: {
+		rect(0, 0, 1, 1, beltColor),
+		rect(0.65, 0.25, 0.15, 0.5, beltArrowColor),
+		rect(0.2, 0.42, 0.45, 0.16, beltArrowColor),
+	},
+	// gates are floor with a thin arrow pointing the way they can be entered
+	game.GateUpToken: {
+		rect(0, 0, 1, 1, floorColor),
+		rect(0.3, 0.15, 0.4, 0.1, gateColor),
+		rect(0.45, 0.25, 0.1, 0.6, gateColor),
+	},
+	game.GateDownToken: {
+		rect(0, 0, 1, 1, floorColor),
+		rect(0.3, 0.75, 0.4, 0.1, gateColor),
+		rect(0.45, 0.15, 0.1, 0.6, gateColor),
+	},
+	game.GateLeftToken: {
+		rect(0, 0, 1, 1, floorColor),
+		rect(0.15, 0.3, 0.1, 0.4, gateColor),
+		rect(0.25, 0.45, 0.6, 0.1, gateColor),
+	},
+	game.GateRightToken: {
+		rect(0, 0, 1, 1, floorColor),
+		rect(0.75, 0.3, 0.1, 0.4, gateColor),
+		rect(0.15, 0.45, 0.6, 0.1, gateColor),
+	},
+}
+
+// art returns the shapes drawn for token.
+func art(token game.Token) []shape {
+	if game.IsTeleporterToken(token) {
+		c := teleporterColors[int(token-game.FirstTeleporterToken)%len(teleporterColors)]
+		return []shape{
+			rect(0, 0, 1, 1, floorColor),
+			circle(0.5, 0.5, 0.4, c),
+			circle(0.5, 0.5, 0.28, floorColor),
+			circle(0.5, 0.5, 0.16, c),
+		}
+	}
+	return tileArt[token]
+}
+
+// layer orders actors that share a tile, lower layers are drawn first.
+func layer(token game.Token) int {
+	switch token {
+	case game.SlimeToken, game.SmallSlimeToken, game.LargeSlimeToken, game.HugeSlimeToken:
+		return 3
+	case game.BoxToken, game.LightBoxToken, game.HeavyBoxToken:
+		return 2
+	case game.ClosedDoorToken, game.LockedDoorToken:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// palette contains every color used by the tile art, it is used for GIF frames.
+func palette() color.Palette {
+	p := color.Palette{floorColor}
+	seen := map[color.RGBA]bool{floorColor: true}
+	for _, token := range tokenOrder {
+		for _, s := range tileArt[token] {
+			if !seen[s.color] {
+				seen[s.color] = true
+				p = append(p, s.color)
+			}
+		}
+	}
+	for _, c := range teleporterColors {
+		p = append(p, c)
+	}
+	return p
+}
+
+// tokenOrder gives map iteration over tileArt a stable order.
+var tokenOrder = []game.Token{
+	game.EmptyToken,
+	game.WallToken,
+	game.PitToken,
+	game.SlimeToken,
+	game.SmallSlimeToken,
+	game.LargeSlimeToken,
+	game.HugeSlimeToken,
+	game.BoxToken,
+	game.LightBoxToken,
+	game.HeavyBoxToken,
+	game.SwitchToken,
+	game.ClosedDoorToken,
+	game.OpenDoorToken,
+	game.SpikeUpToken,
+	game.SpikeDownToken,
+	game.PusherToken,
+	game.PusherActiveToken,
+	game.GoalToken,
+	game.KeyToken,
+	game.LockedDoorToken,
+	game.IceToken,
+	game.CrumblingToken,
+	game.ConveyorUpToken,
+	game.ConveyorDownToken,
+	game.ConveyorLeftToken,
+	game.ConveyorRightToken,
+	game.GateUpToken,
+	game.GateDownToken,
+	game.GateLeftToken,
+	game.GateRightToken,
+}
@@ -0,0 +1,389 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slimesolver/game"
+	"slimesolver/game/math"
+	"sync"
+)
+
+var (
+	errNothingToUndo = errors.New("nothing to undo")
+	errRaceOver      = errors.New("race is over")
+	errSpectator     = errors.New("spectators can't play")
+	errNotPlayer     = errors.New("only the player of a race can play its session")
+	errOrigin        = errors.New("websocket: origin not allowed")
+)
+
+// subscriberBuffer is how many messages can queue up for a slow client
+// before it is disconnected.
+const subscriberBuffer = 64
+
+// subscriber is a WebSocket client receiving the messages of one or more hubs.
+type subscriber struct {
+	send chan []byte
+
+	mu     sync.Mutex
+	closed bool
+}
+
+func newSubscriber() *subscriber {
+	return &subscriber{
+		send: make(chan []byte, subscriberBuffer),
+	}
+}
+
+func (sub *subscriber) close() {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	if !sub.closed {
+		sub.closed = true
+		close(sub.send)
+	}
+}
+
+// deliver queues msg without blocking, a client that can't keep up is closed.
+func (sub *subscriber) deliver(msg []byte) bool {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	if sub.closed {
+		return false
+	}
+
+	select {
+	case sub.send <- msg:
+		return true
+	default:
+		sub.closed = true
+		close(sub.send)
+		return false
+	}
+}
+
+// hub fans messages of a session out to every subscriber watching it.
+type hub struct {
+	mu          sync.Mutex
+	subscribers map[*subscriber]bool
+}
+
+func newHub() *hub {
+	return &hub{
+		subscribers: make(map[*subscriber]bool),
+	}
+}
+
+func (h *hub) add(sub *subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.subscribers[sub] = true
+}
+
+func (h *hub) remove(sub *subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.subscribers, sub)
+}
+
+func (h *hub) len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subscribers)
+}
+
+func (h *hub) broadcast(msg message) {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subscribers {
+		if !sub.deliver(b) {
+			delete(h.subscribers, sub)
+		}
+	}
+}
+
+// race is two sessions of the same level played side by side,
+// the first player to win ends the race.
+type race struct {
+	id       string
+	sessions [2]*session
+
+	mu     sync.Mutex
+	winner int
+}
+
+// finish records player as the winner unless somebody won before.
+func (r *race) finish(player int) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.winner != 0 {
+		return false
+	}
+	r.winner = player
+	return true
+}
+
+func (r *race) getWinner() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.winner
+}
+
+type point struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+func toPoint(v math.Vector2) point {
+	return point{v.X, v.Y}
+}
+
+type eventMessage struct {
+	Token    string `json:"token"`
+	NewToken string `json:"new_token"`
+	From     point  `json:"from"`
+	To       point  `json:"to"`
+}
+
+type reportMessage struct {
+	Turn      int            `json:"turn"`
+	Direction string         `json:"direction"`
+	Moved     []eventMessage `json:"moved"`
+	Changed   []eventMessage `json:"changed"`
+	Killed    []eventMessage `json:"killed"`
+	Spawned   []eventMessage `json:"spawned"`
+	Won       bool           `json:"won"`
+}
+
+func toEvents(events []game.ActorEvent) []eventMessage {
+	l := make([]eventMessage, 0, len(events))
+	for _, e := range events {
+		l = append(l, eventMessage{
+			Token:    string(e.Token),
+			NewToken: string(e.NewToken),
+			From:     toPoint(e.From),
+			To:       toPoint(e.To),
+		})
+	}
+	return l
+}
+
+func toReportMessage(report game.Report) *reportMessage {
+	return &reportMessage{
+		Turn:      report.Turn,
+		Direction: report.Direction.String(),
+		Moved:     toEvents(report.Moved),
+		Changed:   toEvents(report.Changed),
+		Killed:    toEvents(report.Killed),
+		Spawned:   toEvents(report.Spawned),
+		Won:       report.Won,
+	}
+}
+
+// message is sent to WebSocket clients.
+//
+//	state   the current state of a session, sent when connecting and after an undo
+//	turn    the report of a move and the state after it
+//	finish  a race was won by Winner
+//	error   a command from this client failed
+type message struct {
+	Type    string         `json:"type"`
+	Session string         `json:"session,omitempty"`
+	Player  int            `json:"player,omitempty"`
+	State   *State         `json:"state,omitempty"`
+	Report  *reportMessage `json:"report,omitempty"`
+	Winner  int            `json:"winner,omitempty"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// command is received from WebSocket clients.
+type command struct {
+	Type      string `json:"type"` // move or undo
+	Direction string `json:"direction"`
+}
+
+// play makes a move and tells everybody watching the session.
+func (s *Server) play(sess *session, dir game.Direction) (State, error) {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	if sess.race != nil && sess.race.getWinner() != 0 {
+		return State{}, errRaceOver
+	}
+
+	report := sess.game.Play(dir)
+	state := sess.state()
+	sess.hub.broadcast(message{
+		Type:    "turn",
+		Session: sess.id,
+		Player:  sess.player,
+		State:   &state,
+		Report:  toReportMessage(report),
+	})
+
+	// everybody watching a race is subscribed to both sessions
+	if report.Won && sess.race != nil && sess.race.finish(sess.player) {
+		sess.hub.broadcast(message{
+			Type:   "finish",
+			Winner: sess.player,
+		})
+	}
+	return state, nil
+}
+
+// undo reverts the last move and tells everybody watching the session.
+func (s *Server) undo(sess *session) (State, error) {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	if sess.race != nil && sess.race.getWinner() != 0 {
+		return State{}, errRaceOver
+	}
+	if !sess.game.Undo() {
+		return State{}, errNothingToUndo
+	}
+
+	state := sess.state()
+	sess.hub.broadcast(message{
+		Type:    "state",
+		Session: sess.id,
+		Player:  sess.player,
+		State:   &state,
+	})
+	return state, nil
+}
+
+// serveSubscriber streams the turns of sessions to conn, starting with their current state.
+// Commands read from conn are played on player, a nil player makes conn a spectator.
+func (s *Server) serveSubscriber(conn *wsConn, sessions []*session, player *session) {
+	sub := newSubscriber()
+	for _, sess := range sessions {
+		sess.hub.add(sub)
+	}
+	defer func() {
+		for _, sess := range sessions {
+			sess.hub.remove(sub)
+		}
+		sub.close()
+	}()
+
+	// subscribe before reading the state so no turn is missed in between
+	for _, sess := range sessions {
+		b, err := json.Marshal(stateMessage(sess))
+		if err == nil {
+			sub.deliver(b)
+		}
+	}
+
+	go func() {
+		for msg := range sub.send {
+			if err := conn.WriteMessage(msg); err != nil {
+				break
+			}
+		}
+		conn.Close()
+	}()
+
+	for {
+		data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var cmd command
+		err = json.Unmarshal(data, &cmd)
+		if err == nil {
+			err = s.runCommand(player, cmd)
+		}
+		if err != nil {
+			b, _ := json.Marshal(message{Type: "error", Error: err.Error()})
+			sub.deliver(b)
+		}
+	}
+}
+
+func (s *Server) runCommand(sess *session, cmd command) error {
+	if sess == nil {
+		return errSpectator
+	}
+
+	sess.touch(s.now())
+	switch cmd.Type {
+	case "move":
+		dir, err := parseDirection(cmd.Direction)
+		if err != nil {
+			return err
+		}
+		_, err = s.play(sess, dir)
+		return err
+	case "undo":
+		_, err := s.undo(sess)
+		return err
+	}
+	return errors.New("unknown command: " + cmd.Type)
+}
+
+func stateMessage(sess *session) message {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	state := sess.state()
+	return message{
+		Type:    "state",
+		Session: sess.id,
+		Player:  sess.player,
+		State:   &state,
+	}
+}
+
+// handleWebSocket streams the turns of a session, or of both sessions when it is part of a race.
+// Players send commands, ?spectate=1 only watches.
+// Players of a race send their token in the token query parameter.
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request, sess *session) {
+	player := sess
+	if spectate := r.URL.Query().Get("spectate"); spectate == "1" || spectate == "true" {
+		player = nil
+	} else if !sess.authorized(r) {
+		writeError(w, http.StatusForbidden, errNotPlayer)
+		return
+	}
+
+	conn, ok := s.upgrade(w, r)
+	if !ok {
+		return
+	}
+
+	sessions := []*session{sess}
+	if sess.race != nil {
+		sessions = sess.race.sessions[:]
+	}
+	s.serveSubscriber(conn, sessions, player)
+}
+
+// handleRaceWebSocket lets spectators watch both players of a race.
+func (s *Server) handleRaceWebSocket(w http.ResponseWriter, r *http.Request, rc *race) {
+	conn, ok := s.upgrade(w, r)
+	if !ok {
+		return
+	}
+	s.serveSubscriber(conn, rc.sessions[:], nil)
+}
+
+// upgrade turns r into a WebSocket unless it comes from a page of another origin.
+// It writes an error response and returns false if it can't.
+func (s *Server) upgrade(w http.ResponseWriter, r *http.Request) (*wsConn, bool) {
+	if !sameOrigin(r, s.opts.AllowedOrigins) {
+		writeError(w, http.StatusForbidden, errOrigin)
+		return nil, false
+	}
+	conn, err := upgradeWebSocket(w, r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return nil, false
+	}
+	return conn, true
+}
@@ -0,0 +1,230 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func dial(t *testing.T, ts *httptest.Server, path string) *wsConn {
+	t.Helper()
+	conn, err := dialWebSocket("ws"+strings.TrimPrefix(ts.URL, "http")+path, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func send(t *testing.T, conn *wsConn, cmd command) {
+	t.Helper()
+	b, err := json.Marshal(cmd)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := conn.WriteMessage(b); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func receive(t *testing.T, conn *wsConn, wantType string) message {
+	t.Helper()
+	conn.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var msg message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg.Type != wantType {
+		t.Fatalf("expected %s message, got %s", wantType, data)
+	}
+	return msg
+}
+
+func TestWebSocketPlay(t *testing.T) {
+	_, ts := newTestServer(t, Options{})
+	state := createSession(t, ts, "@xD.")
+	path := "/sessions/" + state.ID + "/ws"
+
+	player := dial(t, ts, path)
+	msg := receive(t, player, "state")
+	if msg.Session != state.ID || msg.State.Turn != 0 {
+		t.Fatalf("unexpected initial state: %+v", msg)
+	}
+
+	spectator := dial(t, ts, path+"?spectate=1")
+	receive(t, spectator, "state")
+
+	send(t, player, command{Type: "move", Direction: "right"})
+	for _, conn := range []*wsConn{player, spectator} {
+		msg = receive(t, conn, "turn")
+		report := msg.Report
+		if report.Turn != 1 || report.Direction != "right" || len(report.Moved) != 1 {
+			t.Fatalf("unexpected report: %+v", report)
+		}
+		if report.Moved[0].From != (point{0, 0}) || report.Moved[0].To != (point{1, 0}) {
+			t.Fatalf("expected the slime to move onto the switch, got %+v", report.Moved[0])
+		}
+		if len(report.Changed) != 1 || report.Changed[0].Token != "D" || report.Changed[0].NewToken != "_" {
+			t.Fatalf("expected the door to open, got %+v", report.Changed)
+		}
+	}
+
+	// spectators can only watch
+	send(t, spectator, command{Type: "move", Direction: "left"})
+	msg = receive(t, spectator, "error")
+	if msg.Error != errSpectator.Error() {
+		t.Fatalf("unexpected error: %s", msg.Error)
+	}
+
+	// moves over http are streamed too
+	do(t, http.MethodPost, ts.URL+"/sessions/"+state.ID+"/move", moveRequest{"right"}, nil)
+	msg = receive(t, spectator, "turn")
+	if msg.State.Moves != "rr" {
+		t.Fatalf("expected both moves, got %s", msg.State.Moves)
+	}
+
+	send(t, player, command{Type: "undo"})
+	receive(t, player, "turn")
+	msg = receive(t, player, "state")
+	if msg.State.Moves != "r" {
+		t.Fatalf("expected the last move to be undone, got %s", msg.State.Moves)
+	}
+
+	send(t, player, command{Type: "jump"})
+	receive(t, player, "error")
+}
+
+func TestRace(t *testing.T) {
+	_, ts := newTestServer(t, Options{})
+
+	var created raceResponse
+	status := do(t, http.MethodPost, ts.URL+"/races", createRequest{Level: "@.*"}, &created)
+	if status != http.StatusCreated || len(created.Players) != 2 || created.Players[0].ID == created.Players[1].ID {
+		t.Fatalf("unexpected race %d: %+v", status, created)
+	}
+
+	if len(created.Tokens) != 2 || created.Tokens[0] == created.Tokens[1] {
+		t.Fatalf("expected a token for each player, got %v", created.Tokens)
+	}
+
+	// knowing the session of a player isn't enough to play it
+	url := ts.URL + "/sessions/" + created.Players[0].ID + "/move"
+	if status := do(t, http.MethodPost, url, moveRequest{"right"}, nil); status != http.StatusForbidden {
+		t.Fatalf("expected a move without the token to be forbidden, got %d", status)
+	}
+	if status := do(t, http.MethodPost, url+"?token="+created.Tokens[1], moveRequest{"right"}, nil); status != http.StatusForbidden {
+		t.Fatalf("expected a move with the other player's token to be forbidden, got %d", status)
+	}
+	if _, err := dialWebSocket("ws"+strings.TrimPrefix(ts.URL, "http")+"/sessions/"+created.Players[0].ID+"/ws", nil); err == nil {
+		t.Fatalf("expected a player connection without the token to be rejected")
+	}
+
+	first := dial(t, ts, "/sessions/"+created.Players[0].ID+"/ws?token="+created.Tokens[0])
+	second := dial(t, ts, "/sessions/"+created.Players[1].ID+"/ws?token="+created.Tokens[1])
+	spectator := dial(t, ts, "/races/"+created.ID+"/ws")
+	for _, conn := range []*wsConn{first, second, spectator} {
+		// everybody sees both boards
+		if msg := receive(t, conn, "state"); msg.Player != 1 {
+			t.Fatalf("expected player 1 first, got %d", msg.Player)
+		}
+		if msg := receive(t, conn, "state"); msg.Player != 2 {
+			t.Fatalf("expected player 2 second, got %d", msg.Player)
+		}
+	}
+
+	send(t, second, command{Type: "move", Direction: "right"})
+	for _, conn := range []*wsConn{first, second, spectator} {
+		if msg := receive(t, conn, "turn"); msg.Player != 2 {
+			t.Fatalf("expected a turn of player 2, got %d", msg.Player)
+		}
+	}
+
+	send(t, second, command{Type: "move", Direction: "right"})
+	for _, conn := range []*wsConn{first, second, spectator} {
+		if msg := receive(t, conn, "turn"); !msg.Report.Won {
+			t.Fatalf("expected player 2 to win, got %+v", msg.Report)
+		}
+		if msg := receive(t, conn, "finish"); msg.Winner != 2 {
+			t.Fatalf("expected player 2 to win, got %d", msg.Winner)
+		}
+	}
+
+	send(t, first, command{Type: "move", Direction: "right"})
+	if msg := receive(t, first, "error"); msg.Error != errRaceOver.Error() {
+		t.Fatalf("unexpected error: %s", msg.Error)
+	}
+
+	var race raceResponse
+	do(t, http.MethodGet, ts.URL+"/races/"+created.ID, nil, &race)
+	if race.Winner != 2 || !race.Players[1].Won || race.Players[0].Turn != 0 || len(race.Tokens) != 0 {
+		t.Fatalf("unexpected race: %+v", race)
+	}
+
+	// only the player can delete their session
+	url = ts.URL + "/sessions/" + created.Players[0].ID
+	if status := do(t, http.MethodDelete, url, nil, nil); status != http.StatusForbidden {
+		t.Fatalf("expected a delete without the token to be forbidden, got %d", status)
+	}
+	if status := do(t, http.MethodDelete, url+"?token="+created.Tokens[0], nil, nil); status != http.StatusNoContent {
+		t.Fatalf("expected a delete with the token to succeed, got %d", status)
+	}
+	if status := do(t, http.MethodGet, url, nil, nil); status != http.StatusNotFound {
+		t.Fatalf("expected deleted session to be gone, got %d", status)
+	}
+}
+
+func TestWebSocketFrames(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgradeWebSocket(w, r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		defer conn.Close()
+
+		// echo
+		for {
+			data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			conn.WriteMessage(data)
+		}
+	}))
+	defer ts.Close()
+
+	conn := dial(t, ts, "/")
+	for _, size := range []int{0, 10, 300, 70000} {
+		data := bytes.Repeat([]byte("a"), size)
+		if err := conn.WriteMessage(data); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		conn.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		echo, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !bytes.Equal(echo, data) {
+			t.Fatalf("expected %d bytes back, got %d", size, len(echo))
+		}
+	}
+
+	// plain http requests are rejected
+	resp, err := http.Get(ts.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected bad request, got %d", resp.StatusCode)
+	}
+}
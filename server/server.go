@@ -0,0 +1,613 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slimesolver/game"
+	"slimesolver/levels"
+	"slimesolver/render"
+	"slimesolver/solver"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultSessionTTL      = 30 * time.Minute
+	DefaultSolveTimeout    = 5 * time.Second
+	DefaultMaxSolveTimeout = 30 * time.Second
+	DefaultSweepInterval   = time.Minute
+
+	// maxBodySize caps the JSON body of a request, levels are small
+	maxBodySize = 1 << 20
+)
+
+// Options configure a Server, zero values are replaced by the defaults.
+type Options struct {
+	// SessionTTL is how long a session is kept after it was last used
+	SessionTTL time.Duration
+	// SweepInterval is how often expired sessions are removed from memory
+	SweepInterval time.Duration
+	// SolveTimeout is how long a hint or solution may take when the request doesn't say
+	SolveTimeout time.Duration
+	// MaxSolveTimeout caps the timeout a request can ask for
+	MaxSolveTimeout time.Duration
+	// MaxNodes limits the states expanded by a single solve, 0 means no limit
+	MaxNodes int
+	// AllowedOrigins are the origins besides the server itself whose pages may open
+	// WebSockets, "*" allows every origin
+	AllowedOrigins []string
+}
+
+// Server is an http.Handler that plays levels in memory.
+//
+//	GET    /packs                   built-in level packs
+//	POST   /sessions                create a session from {"level": ...} or {"pack": ..., "name": ...}
+//	GET    /sessions/{id}           session state
+//	DELETE /sessions/{id}           end a session
+//	GET    /sessions/{id}/text      rendered board, ?color=1 adds ANSI colors
+//	POST   /sessions/{id}/move      play {"direction": "up"}
+//	POST   /sessions/{id}/undo      undo the last move
+//	GET    /sessions/{id}/hint      next move towards a solution, guessed when the timeout runs out, ?timeout=2s
+//	GET    /sessions/{id}/solution  full solution from the current state, ?timeout=2s
+//	GET    /sessions/{id}/ws        WebSocket streaming every turn, ?spectate=1 only watches
+//	POST   /races                   create two sessions of the same level, same body as /sessions,
+//	                                the response has a token for each player
+//	GET    /races/{id}              state of both players and the winner
+//	GET    /races/{id}/ws           WebSocket for spectators of both players
+type Server struct {
+	opts Options
+
+	mu       sync.Mutex
+	sessions map[string]*session
+	races    map[string]*race
+
+	// now is replaced in tests to expire sessions
+	now func() time.Time
+
+	// stop ends the sweeping started by New
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func New(opts Options) *Server {
+	if opts.SessionTTL <= 0 {
+		opts.SessionTTL = DefaultSessionTTL
+	}
+	if opts.SolveTimeout <= 0 {
+		opts.SolveTimeout = DefaultSolveTimeout
+	}
+	if opts.MaxSolveTimeout <= 0 {
+		opts.MaxSolveTimeout = DefaultMaxSolveTimeout
+	}
+	if opts.SweepInterval <= 0 {
+		opts.SweepInterval = DefaultSweepInterval
+	}
+
+	s := &Server{
+		opts:     opts,
+		sessions: make(map[string]*session),
+		races:    make(map[string]*race),
+		now:      time.Now,
+		stop:     make(chan struct{}),
+	}
+	go s.sweepEvery(opts.SweepInterval)
+	return s
+}
+
+// Close stops removing expired sessions in the background.
+func (s *Server) Close() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{err.Error()})
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "packs":
+		s.route(w, r, http.MethodGet, s.handlePacks)
+	case len(parts) == 1 && parts[0] == "sessions":
+		s.route(w, r, http.MethodPost, s.handleCreate)
+	case len(parts) >= 2 && parts[0] == "sessions":
+		s.routeSession(w, r, parts[1], parts[2:])
+	case len(parts) == 1 && parts[0] == "races":
+		s.route(w, r, http.MethodPost, s.handleCreateRace)
+	case len(parts) >= 2 && parts[0] == "races":
+		s.routeRace(w, r, parts[1], parts[2:])
+	default:
+		writeError(w, http.StatusNotFound, errors.New("not found"))
+	}
+}
+
+func (s *Server) route(w http.ResponseWriter, r *http.Request, method string, handler http.HandlerFunc) {
+	if r.Method != method {
+		w.Header().Set("Allow", method)
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+	handler(w, r)
+}
+
+type sessionHandler func(w http.ResponseWriter, r *http.Request, sess *session)
+
+func (s *Server) routeSession(w http.ResponseWriter, r *http.Request, id string, rest []string) {
+	sess := s.getSession(id)
+	if sess == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("session %s not found", id))
+		return
+	}
+
+	var method string
+	var handler sessionHandler
+	action := strings.Join(rest, "/")
+	switch action {
+	case "":
+		if r.Method == http.MethodDelete {
+			if !sess.authorized(r) {
+				writeError(w, http.StatusForbidden, errNotPlayer)
+				return
+			}
+			s.deleteSession(id)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		method, handler = http.MethodGet, s.handleState
+	case "text":
+		method, handler = http.MethodGet, s.handleText
+	case "move":
+		method, handler = http.MethodPost, s.handleMove
+	case "undo":
+		method, handler = http.MethodPost, s.handleUndo
+	case "hint":
+		method, handler = http.MethodGet, s.handleHint
+	case "solution":
+		method, handler = http.MethodGet, s.handleSolution
+	case "ws":
+		method, handler = http.MethodGet, s.handleWebSocket
+	default:
+		writeError(w, http.StatusNotFound, errors.New("not found"))
+		return
+	}
+
+	s.route(w, r, method, func(w http.ResponseWriter, r *http.Request) {
+		handler(w, r, sess)
+	})
+}
+
+func (s *Server) routeRace(w http.ResponseWriter, r *http.Request, id string, rest []string) {
+	s.mu.Lock()
+	rc := s.races[id]
+	s.mu.Unlock()
+	if rc != nil {
+		for _, sess := range rc.sessions {
+			if s.getSession(sess.id) == nil {
+				rc = nil
+				break
+			}
+		}
+	}
+	if rc == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("race %s not found", id))
+		return
+	}
+
+	switch strings.Join(rest, "/") {
+	case "":
+		s.route(w, r, http.MethodGet, func(w http.ResponseWriter, r *http.Request) {
+			s.handleRaceState(w, r, rc)
+		})
+	case "ws":
+		s.route(w, r, http.MethodGet, func(w http.ResponseWriter, r *http.Request) {
+			s.handleRaceWebSocket(w, r, rc)
+		})
+	default:
+		writeError(w, http.StatusNotFound, errors.New("not found"))
+	}
+}
+
+// sweepEvery sweeps every interval until the server is closed.
+func (s *Server) sweepEvery(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.sweep()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// sweep removes sessions that haven't been used for longer than the TTL
+// and nobody is watching, races end with their sessions.
+func (s *Server) sweep() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for id, sess := range s.sessions {
+		if s.expired(sess, now) {
+			delete(s.sessions, id)
+		}
+	}
+
+	for id, rc := range s.races {
+		for _, sess := range rc.sessions {
+			if _, ok := s.sessions[sess.id]; !ok {
+				delete(s.races, id)
+				break
+			}
+		}
+	}
+}
+
+// expired reports whether sess wasn't used for longer than the TTL and nobody is watching it.
+func (s *Server) expired(sess *session, now time.Time) bool {
+	sess.mu.Lock()
+	idle := now.Sub(sess.lastUsed) > s.opts.SessionTTL
+	sess.mu.Unlock()
+	return idle && sess.hub.len() == 0
+}
+
+// getSession returns the session and keeps it alive. Sessions that expired
+// since the last sweep are gone already.
+func (s *Server) getSession(id string) *session {
+	s.mu.Lock()
+	sess := s.sessions[id]
+	s.mu.Unlock()
+	if sess == nil {
+		return nil
+	}
+
+	now := s.now()
+	if s.expired(sess, now) {
+		return nil
+	}
+	sess.touch(now)
+	return sess
+}
+
+// decodeJSON reads the JSON body of r into v, bodies over maxBodySize are rejected.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) error {
+	return json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(v)
+}
+
+func (s *Server) deleteSession(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, id)
+}
+
+type levelResponse struct {
+	Name string `json:"name"`
+	Data string `json:"data"`
+}
+
+type packResponse struct {
+	Name   string          `json:"name"`
+	Levels []levelResponse `json:"levels"`
+}
+
+func (s *Server) handlePacks(w http.ResponseWriter, r *http.Request) {
+	packs := make([]packResponse, 0)
+	for _, pack := range levels.Packs() {
+		p := packResponse{Name: pack.Name, Levels: make([]levelResponse, 0)}
+		for _, level := range pack.Levels {
+			p.Levels = append(p.Levels, levelResponse{level.Name, level.Data})
+		}
+		packs = append(packs, p)
+	}
+	writeJSON(w, http.StatusOK, packs)
+}
+
+type createRequest struct {
+	Level string `json:"level"`
+	Pack  string `json:"pack"`
+	Name  string `json:"name"`
+}
+
+// createSession reads a create request and starts a session for its level.
+// It writes an error response and returns nil if the level can't be loaded.
+func (s *Server) createSession(w http.ResponseWriter, req createRequest) *session {
+	data := req.Level
+	if data == "" {
+		level, err := levels.Get(req.Pack, req.Name)
+		if err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return nil
+		}
+		data = level.Data
+	}
+
+	g := game.NewGame(nil)
+	if err := g.Parse(strings.TrimSpace(data)); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return nil
+	}
+
+	sess, err := newSession(data, g, s.now())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return nil
+	}
+
+	s.mu.Lock()
+	s.sessions[sess.id] = sess
+	s.mu.Unlock()
+	return sess
+}
+
+func (s *Server) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var req createRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	sess := s.createSession(w, req)
+	if sess == nil {
+		return
+	}
+	writeJSON(w, http.StatusCreated, stateMessage(sess).State)
+}
+
+type raceResponse struct {
+	ID      string  `json:"id"`
+	Winner  int     `json:"winner"`
+	Players []State `json:"players"`
+	// Tokens are only sent when the race is created, player n moves with Tokens[n-1]
+	Tokens []string `json:"tokens,omitempty"`
+}
+
+func (s *Server) handleCreateRace(w http.ResponseWriter, r *http.Request) {
+	var req createRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	id, err := newSessionID()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	rc := &race{id: id}
+	tokens := make([]string, len(rc.sessions))
+	for i := range rc.sessions {
+		sess := s.createSession(w, req)
+		if sess == nil {
+			for _, created := range rc.sessions[:i] {
+				s.deleteSession(created.id)
+			}
+			return
+		}
+		// the session ids are public to everybody watching, the tokens only go to the players
+		token, err := newSessionID()
+		if err != nil {
+			s.deleteSession(sess.id)
+			for _, created := range rc.sessions[:i] {
+				s.deleteSession(created.id)
+			}
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		sess.race = rc
+		sess.player = i + 1
+		sess.token = token
+		rc.sessions[i] = sess
+		tokens[i] = token
+	}
+
+	s.mu.Lock()
+	s.races[id] = rc
+	s.mu.Unlock()
+
+	resp := newRaceResponse(rc)
+	resp.Tokens = tokens
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+func (s *Server) handleRaceState(w http.ResponseWriter, r *http.Request, rc *race) {
+	writeJSON(w, http.StatusOK, newRaceResponse(rc))
+}
+
+func newRaceResponse(rc *race) raceResponse {
+	resp := raceResponse{
+		ID:     rc.id,
+		Winner: rc.getWinner(),
+	}
+	for _, sess := range rc.sessions {
+		resp.Players = append(resp.Players, *stateMessage(sess).State)
+	}
+	return resp
+}
+
+func (s *Server) handleState(w http.ResponseWriter, r *http.Request, sess *session) {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	writeJSON(w, http.StatusOK, sess.state())
+}
+
+func (s *Server) handleText(w http.ResponseWriter, r *http.Request, sess *session) {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	color := r.URL.Query().Get("color")
+	renderer := render.NewANSI(color == "1" || color == "true")
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte(renderer.Render(sess.game)))
+}
+
+type moveRequest struct {
+	Direction string `json:"direction"`
+}
+
+func parseDirection(s string) (game.Direction, error) {
+	switch strings.ToLower(s) {
+	case "up", "u":
+		return game.Up, nil
+	case "down", "d":
+		return game.Down, nil
+	case "left", "l":
+		return game.Left, nil
+	case "right", "r":
+		return game.Right, nil
+	}
+	return game.Zero, fmt.Errorf("invalid direction: %q", s)
+}
+
+func (s *Server) handleMove(w http.ResponseWriter, r *http.Request, sess *session) {
+	if !sess.authorized(r) {
+		writeError(w, http.StatusForbidden, errNotPlayer)
+		return
+	}
+	var req moveRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	dir, err := parseDirection(req.Direction)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	state, err := s.play(sess, dir)
+	if err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, state)
+}
+
+func (s *Server) handleUndo(w http.ResponseWriter, r *http.Request, sess *session) {
+	if !sess.authorized(r) {
+		writeError(w, http.StatusForbidden, errNotPlayer)
+		return
+	}
+	state, err := s.undo(sess)
+	if err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, state)
+}
+
+// solveTimeout reads the timeout query parameter, capped at MaxSolveTimeout.
+func (s *Server) solveTimeout(r *http.Request) (time.Duration, error) {
+	timeout := s.opts.SolveTimeout
+	if q := r.URL.Query().Get("timeout"); q != "" {
+		d, err := time.ParseDuration(q)
+		if err != nil {
+			return 0, err
+		}
+		if d <= 0 {
+			return 0, fmt.Errorf("timeout must be positive, got %s", q)
+		}
+		timeout = d
+	}
+	if timeout > s.opts.MaxSolveTimeout {
+		timeout = s.opts.MaxSolveTimeout
+	}
+	return timeout, nil
+}
+
+// solve searches for a solution from the current state of the session.
+// It writes an error response and returns false if there is no solution.
+func (s *Server) solve(w http.ResponseWriter, r *http.Request, sess *session) (solver.Result, bool) {
+	timeout, err := s.solveTimeout(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return solver.Result{}, false
+	}
+
+	sess.mu.Lock()
+	g := sess.game.Clone()
+	sess.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+	result, err := solver.Solve(ctx, g, solver.Options{MaxNodes: s.opts.MaxNodes, Algorithm: solver.AStar, Prune: true})
+	switch {
+	case err == nil:
+		return result, true
+	case errors.Is(err, solver.ErrNoSolution):
+		writeError(w, http.StatusUnprocessableEntity, err)
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, solver.ErrLimit):
+		writeError(w, http.StatusServiceUnavailable, fmt.Errorf("no solution found in time: %w", err))
+	default:
+		writeError(w, http.StatusInternalServerError, err)
+	}
+	return solver.Result{}, false
+}
+
+type hintResponse struct {
+	Direction  string `json:"direction,omitempty"`
+	MovesLeft  int    `json:"moves_left,omitempty"`
+	Confidence string `json:"confidence"`
+	Undo       int    `json:"undo,omitempty"`
+}
+
+func (s *Server) handleHint(w http.ResponseWriter, r *http.Request, sess *session) {
+	timeout, err := s.solveTimeout(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	sess.mu.Lock()
+	if sess.game.Won() {
+		sess.mu.Unlock()
+		writeError(w, http.StatusConflict, errors.New("level is already won"))
+		return
+	}
+	// the history tells how many moves to undo when the level can't be won anymore
+	g := sess.game.CloneWithHistory()
+	sess.mu.Unlock()
+
+	hint := solver.Hint(r.Context(), g, solver.Budget{Time: timeout, Nodes: s.opts.MaxNodes})
+	resp := hintResponse{
+		MovesLeft:  hint.MovesLeft,
+		Confidence: hint.Confidence.String(),
+		Undo:       hint.Undo,
+	}
+	if hint.Direction != game.Zero {
+		resp.Direction = hint.Direction.String()
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+type solutionResponse struct {
+	Moves    string `json:"moves"`
+	Length   int    `json:"length"`
+	Expanded int    `json:"expanded"`
+}
+
+func (s *Server) handleSolution(w http.ResponseWriter, r *http.Request, sess *session) {
+	result, ok := s.solve(w, r, sess)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, solutionResponse{
+		Moves:    game.FormatMoves(result.Moves),
+		Length:   len(result.Moves),
+		Expanded: result.Expanded,
+	})
+}
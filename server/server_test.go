@@ -0,0 +1,276 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestServer(t *testing.T, opts Options) (*Server, *httptest.Server) {
+	t.Helper()
+	s := New(opts)
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+	t.Cleanup(s.Close)
+	return s, ts
+}
+
+// do sends a request with an optional JSON body and decodes the JSON response into out.
+func do(t *testing.T, method, url string, body any, out any) int {
+	t.Helper()
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		r = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequest(method, url, r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func createSession(t *testing.T, ts *httptest.Server, level string) State {
+	t.Helper()
+	var state State
+	status := do(t, http.MethodPost, ts.URL+"/sessions", createRequest{Level: level}, &state)
+	if status != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, status)
+	}
+	return state
+}
+
+func TestPlay(t *testing.T) {
+	_, ts := newTestServer(t, Options{})
+	state := createSession(t, ts, "@B.*")
+	if state.ID == "" || state.Width != 4 || state.Height != 1 || len(state.Actors) != 2 {
+		t.Fatalf("unexpected state: %+v", state)
+	}
+	if state.Board[0] != "...*" {
+		t.Fatalf("expected the board without actors, got %q", state.Board[0])
+	}
+	url := ts.URL + "/sessions/" + state.ID
+
+	status := do(t, http.MethodPost, url+"/move", moveRequest{"right"}, &state)
+	if status != http.StatusOK || state.Turn != 1 || state.Moves != "r" {
+		t.Fatalf("unexpected move response %d: %+v", status, state)
+	}
+	if state.Actors[0] != (ActorState{"@", 1, 0}) || state.Actors[1] != (ActorState{"B", 2, 0}) {
+		t.Fatalf("expected the slime to push the box, got %+v", state.Actors)
+	}
+
+	var errResp errorResponse
+	status = do(t, http.MethodPost, url+"/move", moveRequest{"sideways"}, &errResp)
+	if status != http.StatusBadRequest || errResp.Error == "" {
+		t.Fatalf("expected invalid direction error, got %d %+v", status, errResp)
+	}
+
+	resp, err := http.Get(url + "/text")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(text) != ".@B*\n" {
+		t.Fatalf("unexpected text: %q", text)
+	}
+
+	status = do(t, http.MethodPost, url+"/undo", nil, &state)
+	if status != http.StatusOK || state.Turn != 0 || state.Actors[0] != (ActorState{"@", 0, 0}) {
+		t.Fatalf("unexpected undo response %d: %+v", status, state)
+	}
+	status = do(t, http.MethodPost, url+"/undo", nil, &errResp)
+	if status != http.StatusConflict {
+		t.Fatalf("expected nothing to undo, got %d", status)
+	}
+
+	status = do(t, http.MethodGet, url+"/move", nil, &errResp)
+	if status != http.StatusMethodNotAllowed {
+		t.Fatalf("expected method not allowed, got %d", status)
+	}
+
+	status = do(t, http.MethodDelete, url, nil, nil)
+	if status != http.StatusNoContent {
+		t.Fatalf("expected no content, got %d", status)
+	}
+	status = do(t, http.MethodGet, url, nil, &errResp)
+	if status != http.StatusNotFound {
+		t.Fatalf("expected deleted session to be gone, got %d", status)
+	}
+}
+
+func TestCreateErrors(t *testing.T) {
+	_, ts := newTestServer(t, Options{})
+	tt := []struct {
+		name   string
+		req    createRequest
+		status int
+	}{
+		{
+			name:   "invalid level",
+			req:    createRequest{Level: "@?"},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "missing pack",
+			req:    createRequest{Pack: "missing", Name: "level"},
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "built-in level",
+			req:    createRequest{Pack: "tutorial", Name: "01-first-steps"},
+			status: http.StatusCreated,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			status := do(t, http.MethodPost, ts.URL+"/sessions", tc.req, nil)
+			if status != tc.status {
+				t.Fatalf("expected status %d, got %d", tc.status, status)
+			}
+		})
+	}
+}
+
+func TestSolve(t *testing.T) {
+	_, ts := newTestServer(t, Options{})
+	state := createSession(t, ts, "@BO.*")
+	url := ts.URL + "/sessions/" + state.ID
+
+	var hint hintResponse
+	status := do(t, http.MethodGet, url+"/hint", nil, &hint)
+	if status != http.StatusOK || hint.Direction != "right" || hint.MovesLeft != 4 || hint.Confidence != "proven" {
+		t.Fatalf("unexpected hint %d: %+v", status, hint)
+	}
+
+	do(t, http.MethodPost, url+"/move", moveRequest{"right"}, nil)
+	var solution solutionResponse
+	status = do(t, http.MethodGet, url+"/solution?timeout=1s", nil, &solution)
+	if status != http.StatusOK || solution.Moves != "rrr" || solution.Length != 3 {
+		t.Fatalf("unexpected solution %d: %+v", status, solution)
+	}
+
+	var errResp errorResponse
+	for _, timeout := range []string{"soon", "0s", "-1s"} {
+		status = do(t, http.MethodGet, url+"/solution?timeout="+timeout, nil, &errResp)
+		if status != http.StatusBadRequest {
+			t.Fatalf("expected invalid timeout %s, got %d", timeout, status)
+		}
+		// a hint without a timeout would search for as long as it takes
+		status = do(t, http.MethodGet, url+"/hint?timeout="+timeout, nil, &errResp)
+		if status != http.StatusBadRequest {
+			t.Fatalf("expected invalid hint timeout %s, got %d", timeout, status)
+		}
+	}
+
+	state = createSession(t, ts, "@O*")
+	status = do(t, http.MethodGet, ts.URL+"/sessions/"+state.ID+"/solution", nil, &errResp)
+	if status != http.StatusUnprocessableEntity {
+		t.Fatalf("expected no solution, got %d", status)
+	}
+}
+
+func TestHintUnwinnable(t *testing.T) {
+	_, ts := newTestServer(t, Options{})
+	state := createSession(t, ts, "....#\n.B@O*")
+	url := ts.URL + "/sessions/" + state.ID
+
+	// pushing the box against the edge loses the level
+	do(t, http.MethodPost, url+"/move", moveRequest{"left"}, nil)
+	do(t, http.MethodPost, url+"/move", moveRequest{"up"}, nil)
+
+	var hint hintResponse
+	status := do(t, http.MethodGet, url+"/hint", nil, &hint)
+	if status != http.StatusOK || hint.Direction != "" || hint.Confidence != "unwinnable" || hint.Undo != 2 {
+		t.Fatalf("unexpected hint %d: %+v", status, hint)
+	}
+}
+
+func TestSolveLimit(t *testing.T) {
+	_, ts := newTestServer(t, Options{MaxNodes: 2})
+	state := createSession(t, ts, "@.......*")
+
+	var errResp errorResponse
+	status := do(t, http.MethodGet, ts.URL+"/sessions/"+state.ID+"/solution", nil, &errResp)
+	if status != http.StatusServiceUnavailable || !strings.Contains(errResp.Error, "in time") {
+		t.Fatalf("expected the search to give up, got %d %+v", status, errResp)
+	}
+
+	// hints guess instead
+	var hint hintResponse
+	status = do(t, http.MethodGet, ts.URL+"/sessions/"+state.ID+"/hint", nil, &hint)
+	if status != http.StatusOK || hint.Direction != "right" || hint.Confidence != "guess" {
+		t.Fatalf("unexpected hint %d: %+v", status, hint)
+	}
+}
+
+func TestPacks(t *testing.T) {
+	_, ts := newTestServer(t, Options{})
+
+	var packs []packResponse
+	status := do(t, http.MethodGet, ts.URL+"/packs", nil, &packs)
+	if status != http.StatusOK || len(packs) == 0 || len(packs[0].Levels) == 0 {
+		t.Fatalf("unexpected packs %d: %+v", status, packs)
+	}
+}
+
+func TestSessionExpiry(t *testing.T) {
+	s, ts := newTestServer(t, Options{SessionTTL: time.Minute})
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	state := createSession(t, ts, "@.*")
+	url := ts.URL + "/sessions/" + state.ID
+
+	// using a session keeps it alive
+	now = now.Add(50 * time.Second)
+	if status := do(t, http.MethodGet, url, nil, nil); status != http.StatusOK {
+		t.Fatalf("expected session to exist, got %d", status)
+	}
+	now = now.Add(50 * time.Second)
+	if status := do(t, http.MethodGet, url, nil, nil); status != http.StatusOK {
+		t.Fatalf("expected session to exist, got %d", status)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if status := do(t, http.MethodGet, url, nil, nil); status != http.StatusNotFound {
+		t.Fatalf("expected session to expire, got %d", status)
+	}
+
+	// the expired session is dropped by the next sweep
+	s.sweep()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.sessions) != 0 {
+		t.Fatalf("expected no sessions after sweeping, got %d", len(s.sessions))
+	}
+}
+
+func TestBodyLimit(t *testing.T) {
+	_, ts := newTestServer(t, Options{})
+	body := map[string]string{"level": strings.Repeat(".", maxBodySize) + "@*"}
+	if status := do(t, http.MethodPost, ts.URL+"/sessions", body, nil); status != http.StatusBadRequest {
+		t.Fatalf("expected a body over the limit to be rejected, got %d", status)
+	}
+}
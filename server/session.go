@@ -0,0 +1,131 @@
+package server
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"slimesolver/game"
+	"strings"
+	"sync"
+	"time"
+)
+
+type session struct {
+	// mu guards game, solving works on a clone so it doesn't hold the lock
+	mu       sync.Mutex
+	id       string
+	level    string
+	game     *game.Game
+	lastUsed time.Time
+
+	// hub sends the turns of this session to WebSocket clients
+	hub *hub
+
+	// race is set for sessions that are one side of a race, player is 1 or 2
+	// and only requests carrying token may play it
+	race   *race
+	player int
+	token  string
+}
+
+func newSession(level string, g *game.Game, now time.Time) (*session, error) {
+	id, err := newSessionID()
+	if err != nil {
+		return nil, err
+	}
+
+	return &session{
+		id:       id,
+		level:    level,
+		game:     g,
+		lastUsed: now,
+		hub:      newHub(),
+	}, nil
+}
+
+func (s *session) touch(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastUsed = now
+}
+
+// authorized reports whether r may play the session. Anybody who knows its id plays
+// a session of their own, the player of a race has to send its token as well,
+// either as a bearer token or in the token query parameter.
+func (s *session) authorized(r *http.Request) bool {
+	if s.race == nil {
+		return true
+	}
+	token := r.URL.Query().Get("token")
+	if auth, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		token = auth
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1
+}
+
+func newSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+type ActorState struct {
+	Token string `json:"token"`
+	X     int    `json:"x"`
+	Y     int    `json:"y"`
+}
+
+// State is the JSON representation of a session.
+type State struct {
+	ID     string       `json:"id"`
+	Width  int          `json:"width"`
+	Height int          `json:"height"`
+	Board  []string     `json:"board"`
+	Actors []ActorState `json:"actors"`
+	Turn   int          `json:"turn"`
+	Moves  string       `json:"moves"`
+	Won    bool         `json:"won"`
+}
+
+// state must be called with the session locked.
+func (s *session) state() State {
+	g := s.game
+
+	// the static board without actors on it
+	board := make([]string, g.Height())
+	for y := range board {
+		var sb strings.Builder
+		for x := 0; x < g.Width(); x++ {
+			sb.WriteRune(rune(g.GetTokenAt(x, y)))
+		}
+		board[y] = sb.String()
+	}
+
+	actors := make([]ActorState, 0)
+	for _, actor := range g.Actors() {
+		actors = append(actors, actorState(actor))
+	}
+
+	return State{
+		ID:     s.id,
+		Width:  g.Width(),
+		Height: g.Height(),
+		Board:  board,
+		Actors: actors,
+		Turn:   g.Turn(),
+		Moves:  game.FormatMoves(g.History()),
+		Won:    g.Won(),
+	}
+}
+
+func actorState(actor game.Actor) ActorState {
+	pos := actor.GetPosition()
+	return ActorState{
+		Token: actor.String(),
+		X:     pos.X,
+		Y:     pos.Y,
+	}
+}
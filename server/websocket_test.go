@@ -0,0 +1,122 @@
+package server
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// dialWebSocket opens a client connection to a ws:// url, sending header with the handshake.
+func dialWebSocket(rawURL string, header http.Header) (*wsConn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "ws" {
+		return nil, fmt.Errorf("websocket: unsupported scheme %s", u.Scheme)
+	}
+
+	conn, err := net.Dial("tcp", u.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(b)
+
+	rw := bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+	fmt.Fprintf(rw, "GET %s HTTP/1.1\r\n"+
+		"Host: %s\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: %s\r\n"+
+		"Sec-WebSocket-Version: 13\r\n", u.RequestURI(), u.Host, key)
+	header.Write(rw)
+	rw.WriteString("\r\n")
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	resp, err := http.ReadResponse(rw.Reader, nil)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		body, _ := io.ReadAll(resp.Body)
+		conn.Close()
+		return nil, fmt.Errorf("websocket: handshake failed with %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	if resp.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		conn.Close()
+		return nil, errors.New("websocket: invalid accept key")
+	}
+
+	return &wsConn{conn: conn, rw: rw, client: true}, nil
+}
+
+func TestUnmaskedFrame(t *testing.T) {
+	_, ts := newTestServer(t, Options{})
+	state := createSession(t, ts, "@.*")
+	conn := dial(t, ts, "/sessions/"+state.ID+"/ws")
+	receive(t, conn, "state")
+
+	// a server has to close the connection when a client doesn't mask its frames
+	conn.client = false
+	if err := conn.writeFrame(opText, []byte(`{"type":"move","direction":"right"}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	conn.client = true
+	conn.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, opcode, payload, err := conn.readFrame()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opcode != opClose || len(payload) < 2 || binary.BigEndian.Uint16(payload) != closeProtocolError {
+		t.Fatalf("expected a close frame with status %d, got opcode %d %v", closeProtocolError, opcode, payload)
+	}
+}
+
+func TestWebSocketOrigin(t *testing.T) {
+	_, ts := newTestServer(t, Options{AllowedOrigins: []string{"https://slimes.example"}})
+	state := createSession(t, ts, "@.*")
+	url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/sessions/" + state.ID + "/ws"
+
+	tt := []struct {
+		origin string
+		ok     bool
+	}{
+		{"", true},
+		{ts.URL, true},
+		{"https://slimes.example", true},
+		{"https://evil.example", false},
+	}
+	for _, tc := range tt {
+		header := http.Header{}
+		if tc.origin != "" {
+			header.Set("Origin", tc.origin)
+		}
+		conn, err := dialWebSocket(url, header)
+		if (err == nil) != tc.ok {
+			t.Fatalf("origin %q: expected ok %v, got %v", tc.origin, tc.ok, err)
+		}
+		if err == nil {
+			conn.Close()
+		}
+	}
+}
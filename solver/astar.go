@@ -0,0 +1,122 @@
+package solver
+
+import (
+	"container/heap"
+	"context"
+	"slimesolver/game"
+)
+
+type openNode struct {
+	game *game.Game
+	node *node
+	key  string
+	// estimated length of a solution through this state
+	cost int
+	// order it was added in, ties are broken first in first out so searches are repeatable
+	order int
+}
+
+// openList is a priority queue of the states to expand next, cheapest first.
+type openList []*openNode
+
+func (l openList) Len() int { return len(l) }
+
+func (l openList) Less(i, j int) bool {
+	if l[i].cost != l[j].cost {
+		return l[i].cost < l[j].cost
+	}
+	// prefer the state that is further along
+	if l[i].node.depth != l[j].node.depth {
+		return l[i].node.depth > l[j].node.depth
+	}
+	return l[i].order < l[j].order
+}
+
+func (l openList) Swap(i, j int) { l[i], l[j] = l[j], l[i] }
+
+func (l *openList) Push(x any) { *l = append(*l, x.(*openNode)) }
+
+func (l *openList) Pop() any {
+	old := *l
+	n := old[len(old)-1]
+	old[len(old)-1] = nil
+	*l = old[:len(old)-1]
+	return n
+}
+
+func solveAStar(ctx context.Context, g *game.Game, opts Options) (Result, error) {
+	result := Result{}
+	h := opts.heuristic()
+	estimate := h.New(g)
+	analyzer := opts.analyzer(g)
+
+	start := g.Clone()
+	startKey := start.Key()
+	// shortest known number of moves to every state seen
+	best := map[string]int{startKey: 0}
+
+	open := &openList{}
+	order := 0
+	if cost := estimate(start); cost < Unreachable {
+		heap.Push(open, &openNode{start, &node{}, startKey, cost, order})
+	}
+
+	for open.Len() > 0 {
+		if result.Expanded%cancelCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				result.Visited = len(best)
+				return result, err
+			}
+		}
+
+		result.Peak = max(result.Peak, open.Len()+len(best))
+		current := heap.Pop(open).(*openNode)
+		if current.node.depth > best[current.key] {
+			// a shorter way here was found after this was queued
+			continue
+		}
+
+		// winning is checked when a state is expanded, not when it is queued,
+		// so nothing cheaper is left in the open list
+		if current.game.Won() {
+			result.Moves = current.node.moves()
+			result.Visited = len(best)
+			result.Optimal = h.Admissible
+			return result, nil
+		}
+
+		if opts.MaxNodes > 0 && result.Expanded >= opts.MaxNodes {
+			result.Visited = len(best)
+			return result, ErrLimit
+		}
+		result.Expanded++
+
+		for _, dir := range Directions {
+			next := current.game.Clone()
+			next.Move(dir)
+			if Lost(next) {
+				continue
+			}
+
+			depth := current.node.depth + 1
+			key := next.Key()
+			if d, ok := best[key]; ok && d <= depth {
+				continue
+			}
+			best[key] = depth
+			if prune(analyzer, next, &result) {
+				continue
+			}
+
+			cost := estimate(next)
+			if cost >= Unreachable {
+				continue
+			}
+			order++
+			heap.Push(open, &openNode{next, &node{current.node, dir, depth}, key, depth + cost, order})
+		}
+	}
+
+	result.Visited = len(best)
+	return result, ErrNoSolution
+}
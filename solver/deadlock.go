@@ -0,0 +1,252 @@
+package solver
+
+import (
+	"slimesolver/game"
+	"slimesolver/game/math"
+)
+
+// Rule names a reason a state can never be won.
+type Rule string
+
+const (
+	// DeadBox is when there are fewer boxes that can still be pushed into a pit
+	// than pits that have to be filled for a slime to reach a goal, slimes walk
+	// over pits that don't kill them
+	DeadBox Rule = "dead box"
+	// WalledIn is when a goal can only be reached through a door
+	// but no slime can get to a switch to open it and no box is carried to one
+	WalledIn Rule = "walled in"
+	// NoPresser is when a goal can only be reached through a door
+	// but nothing left can press a switch, small slimes only press one while growing
+	// unless the rules let them
+	NoPresser Rule = "no presser"
+)
+
+// Rules lists every rule in the order they are checked.
+var Rules = []Rule{DeadBox, WalledIn, NoPresser}
+
+// Analyzer finds states reachable from a level that can never be won.
+type Analyzer struct {
+	// squares from which a box can still be pushed into a pit or onto a switch,
+	// walls never change so this is computed once
+	pitLive    [][]bool
+	switchLive [][]bool
+	goals      []math.Vector2
+	doors      map[math.Vector2]bool
+}
+
+// NewAnalyzer computes the dead squares of the board of start.
+func NewAnalyzer(start *game.Game) *Analyzer {
+	a := &Analyzer{
+		goals: goals(start),
+		doors: make(map[math.Vector2]bool),
+	}
+
+	var pits, switches []math.Vector2
+	for y := 0; y < start.Height(); y++ {
+		for x := 0; x < start.Width(); x++ {
+			// crumbling floor turns into pits that might have to be filled
+			if start.IsPit(x, y) || start.IsCrumbling(x, y) {
+				pits = append(pits, math.Vector2{X: x, Y: y})
+			}
+		}
+	}
+	for _, actor := range start.Actors() {
+		switch actor.Token() {
+		case game.SwitchToken:
+			switches = append(switches, actor.GetPosition())
+		case game.ClosedDoorToken, game.OpenDoorToken:
+			a.doors[actor.GetPosition()] = true
+		}
+	}
+
+	a.pitLive = liveSquares(start, pits)
+	a.switchLive = liveSquares(start, switches)
+	return a
+}
+
+// liveSquares marks every square a box can be pushed from into one of targets.
+// Boxes can't be pulled, so pushes are walked backwards from the targets:
+// a box reaches t from t-d when both it and whatever pushes it at t-2d aren't in a wall.
+func liveSquares(g *game.Game, targets []math.Vector2) [][]bool {
+	live := make([][]bool, g.Height())
+	for y := range live {
+		live[y] = make([]bool, g.Width())
+	}
+
+	queue := make([]math.Vector2, 0, len(targets))
+	for _, t := range targets {
+		live[t.Y][t.X] = true
+		queue = append(queue, t)
+	}
+	for len(queue) > 0 {
+		t := queue[0]
+		queue = queue[1:]
+
+		// a box pushed onto the partner pad of t can come out on t
+		if partner, ok := g.Partner(t); ok && !live[partner.Y][partner.X] {
+			live[partner.Y][partner.X] = true
+			queue = append(queue, partner)
+		}
+
+		for _, dir := range Directions {
+			from := step(t, opposite(dir))
+			pusher := step(from, opposite(dir))
+			if g.IsWallOrEdge(from.X, from.Y) || live[from.Y][from.X] {
+				continue
+			}
+			// gates only let the box onto t and the pusher onto t-d moving along d
+			if g.IsBlocked(t, dir) {
+				continue
+			}
+			// a conveyor at t-d pointing at t carries the box without a pusher
+			if carry, ok := g.Conveyor(from.X, from.Y); (!ok || carry != dir) &&
+				(g.IsWallOrEdge(pusher.X, pusher.Y) || g.IsBlocked(from, dir)) {
+				continue
+			}
+			live[from.Y][from.X] = true
+			queue = append(queue, from)
+		}
+	}
+	return live
+}
+
+func opposite(dir game.Direction) game.Direction {
+	switch dir {
+	case game.Up:
+		return game.Down
+	case game.Down:
+		return game.Up
+	case game.Left:
+		return game.Right
+	case game.Right:
+		return game.Left
+	}
+	return dir
+}
+
+// DeadSquare reports whether a box at x, y can never be pushed into a pit or onto a switch.
+func (a *Analyzer) DeadSquare(x, y int) bool {
+	return !a.pitLive[y][x] && !a.switchLive[y][x]
+}
+
+// Check returns the first rule proving g can never be won.
+func (a *Analyzer) Check(g *game.Game) (Rule, bool) {
+	if g.Won() {
+		return "", false
+	}
+	if a.deadBox(g) {
+		return DeadBox, true
+	}
+	return a.door(g)
+}
+
+func (a *Analyzer) deadBox(g *game.Game) bool {
+	if !g.Rules().PitsKill {
+		return false
+	}
+	positions := slimes(g)
+	var maps []costMap
+	for _, goal := range a.goals {
+		maps = append(maps, newCostMap(g, goal, func(x, y int) int {
+			if g.IsPit(x, y) {
+				return 1
+			}
+			return 0
+		}))
+	}
+	pits := worst(maps, positions)
+	if pits == 0 || pits >= Unreachable {
+		return false
+	}
+
+	boxes := 0
+	for _, box := range g.GetActorsWithTokens([]game.Token{game.BoxToken, game.LightBoxToken, game.HeavyBoxToken}) {
+		pos := box.GetPosition()
+		if a.pitLive[pos.Y][pos.X] {
+			boxes++
+		}
+	}
+	return boxes < pits
+}
+
+// door checks the goals that can only be reached by opening a door.
+// Slimes can't walk through closed doors, so everything they can reach until a
+// switch is pressed is what they can reach with every door closed.
+func (a *Analyzer) door(g *game.Game) (Rule, bool) {
+	if len(a.doors) == 0 {
+		return "", false
+	}
+
+	region := a.slimeRegion(g)
+	needsDoor := false
+	for _, goal := range a.goals {
+		if !region[goal] {
+			needsDoor = true
+			break
+		}
+	}
+	if !needsDoor {
+		return "", false
+	}
+
+	reachable := false
+	large, small := 0, 0
+	// boxes outside the region can still be carried onto a switch by conveyors
+	boxes, carried := false, false
+	for _, actor := range g.Actors() {
+		pos := actor.GetPosition()
+		if game.IsBoxToken(actor.Token()) && a.switchLive[pos.Y][pos.X] {
+			boxes = true
+			carried = carried || !region[pos]
+		}
+		if !region[pos] {
+			continue
+		}
+		switch size := game.SlimeSize(actor.Token()); {
+		case actor.Token() == game.SwitchToken:
+			reachable = true
+		case size > 1:
+			large++
+		case size == 1:
+			small++
+		}
+	}
+	if !reachable && !carried {
+		return WalledIn, true
+	}
+	if g.Rules().SmallSlimesPress {
+		large += small
+	}
+	// two small slimes can still grow into a large one unless the rules keep slimes small
+	if large == 0 && (small < 2 || g.Rules().SlimeSize < 2) && !boxes {
+		return NoPresser, true
+	}
+	return "", false
+}
+
+// slimeRegion is every square a slime can walk to without passing a door.
+func (a *Analyzer) slimeRegion(g *game.Game) map[math.Vector2]bool {
+	region := make(map[math.Vector2]bool)
+	queue := slimes(g)
+	for _, pos := range queue {
+		region[pos] = true
+	}
+	for len(queue) > 0 {
+		pos := queue[0]
+		queue = queue[1:]
+		if partner, ok := g.Partner(pos); ok && !region[partner] {
+			region[partner] = true
+			queue = append(queue, partner)
+		}
+		for _, dir := range Directions {
+			next := step(pos, dir)
+			if g.IsWallOrEdge(next.X, next.Y) || a.doors[next] || region[next] {
+				continue
+			}
+			region[next] = true
+			queue = append(queue, next)
+		}
+	}
+	return region
+}
@@ -0,0 +1,192 @@
+package solver
+
+import (
+	"context"
+	"slimesolver/game"
+	"testing"
+)
+
+func TestDeadlocks(t *testing.T) {
+	tt := []struct {
+		name   string
+		state  string
+		inputs []game.Direction
+		// empty when the state can still be won
+		want Rule
+	}{
+		{
+			name:  "box next to pit",
+			state: `@BO*`,
+		},
+		{
+			name: "box in corner",
+			state: `B#..
+					@.O*`,
+			want: DeadBox,
+		},
+		{
+			name: "box against edge",
+			state: `...#
+					B@O*`,
+			want: DeadBox,
+		},
+		{
+			name: "pit around the corner",
+			state: `@BO.
+					...*`,
+		},
+		{
+			name: "switch behind door",
+			state: `@#x
+					.D*`,
+			want: WalledIn,
+		},
+		{
+			name: "switch next to slime",
+			state: `@x.
+					##D
+					..*`,
+		},
+		{
+			name: "small slime can't press switch",
+			state: `ox.
+					##D
+					..*`,
+			want: NoPresser,
+		},
+		{
+			name: "small slimes can grow",
+			state: `oxo
+					##D
+					..*`,
+		},
+		{
+			name: "box can press switch",
+			state: `oBx.
+					###D
+					...*`,
+		},
+		{
+			name: "box in corner can't press switch",
+			state: `Bo#x.
+					####D
+					....*`,
+			want: WalledIn,
+		},
+		{
+			name: "box carried into a pit",
+			state: `.@.
+					#B#
+					#>O
+					##*`,
+		},
+		{
+			// the large slime falls into the pit, leaving the box on
+			// its way to the switch outside the small slime's region
+			name: "box carried onto switch",
+			state: `o#@...
+					D#BO..
+					*#>>>x`,
+			inputs: []game.Direction{game.Down, game.Right},
+		},
+		{
+			name:  "box pushed through a gate",
+			state: `@BrO*`,
+		},
+		{
+			name: "box pushed against a gate",
+			state: `....#
+					@BlO*`,
+			want: DeadBox,
+		},
+		{
+			name: "small slime presses switch by the rules",
+			state: `; small-slimes-press = on
+					ox.
+					##D
+					..*`,
+		},
+		{
+			name: "pits don't have to be filled by the rules",
+			state: `; pits-kill = off
+					B#..
+					@.O*`,
+		},
+		{
+			name:  "won",
+			state: `B#*`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			g := newGame(t, tc.state)
+			for _, dir := range tc.inputs {
+				g.Move(dir)
+			}
+			rule, dead := NewAnalyzer(g).Check(g)
+			if rule != tc.want || dead != (tc.want != "") {
+				t.Fatalf("expected %q, got %q", tc.want, rule)
+			}
+		})
+	}
+}
+
+func TestDeadSquare(t *testing.T) {
+	g := newGame(t, `B#..x
+					 @.O*.
+					 .....`)
+	a := NewAnalyzer(g)
+
+	tt := []struct {
+		x, y int
+		want bool
+	}{
+		{0, 0, true},
+		{1, 1, false},
+		{2, 1, false},
+		{4, 0, false},
+		{3, 0, false},
+		{0, 2, true},
+		{4, 2, true},
+	}
+	for _, tc := range tt {
+		if got := a.DeadSquare(tc.x, tc.y); got != tc.want {
+			t.Fatalf("expected dead square at (%d, %d) to be %v", tc.x, tc.y, tc.want)
+		}
+	}
+}
+
+func TestSolvePrune(t *testing.T) {
+	for _, algorithm := range []Algorithm{BFS, AStar, IDAStar} {
+		for _, tc := range solveCases {
+			t.Run(algorithm.String()+"/"+tc.name, func(t *testing.T) {
+				result, err := Solve(context.Background(), newGame(t, tc.state), Options{Algorithm: algorithm, Prune: true})
+				if tc.length == -1 {
+					if err != ErrNoSolution {
+						t.Fatalf("expected no solution, got %v", err)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if len(result.Moves) != tc.length {
+					t.Fatalf("expected %d moves, got %d", tc.length, len(result.Moves))
+				}
+				replay(t, tc.state, result.Moves)
+			})
+		}
+	}
+
+	// pushing the box left first sticks it against the edge
+	state := `....#
+			  .B@O*`
+	result, err := Solve(context.Background(), newGame(t, state), Options{Prune: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Moves) != 8 || result.Pruned[DeadBox] == 0 {
+		t.Fatalf("expected 8 moves with dead boxes pruned, got %d %v", len(result.Moves), result.Pruned)
+	}
+}
@@ -0,0 +1,130 @@
+package solver
+
+import (
+	"context"
+	"slimesolver/game"
+)
+
+// Enumeration is every shortest solution of a level, up to a limit.
+type Enumeration struct {
+	// Solutions are distinct move sequences of Length moves
+	Solutions [][]game.Direction
+	Length    int
+	// Total is how many shortest solutions there are, even past the limit
+	Total int
+	// Expanded is the number of states whose moves were tried
+	Expanded int
+}
+
+// shortestNode is a state and every move leading to it in the fewest moves.
+type shortestNode struct {
+	depth   int
+	parents []shortestEdge
+	// number of shortest move sequences leading here
+	paths int
+}
+
+type shortestEdge struct {
+	parent *shortestNode
+	dir    game.Direction
+}
+
+type enumerated struct {
+	game *game.Game
+	node *shortestNode
+}
+
+// maxPaths caps Total so it doesn't overflow on wide open levels
+const maxPaths = 1 << 40
+
+// Enumerate finds up to limit distinct shortest solutions of g with a breadth first search
+// that remembers every shortest way into a state. limit 0 means no limit.
+// opts.MaxNodes and opts.Prune are used, the other options are not.
+// g is not modified.
+func Enumerate(ctx context.Context, g *game.Game, limit int, opts Options) (Enumeration, error) {
+	result := Enumeration{}
+	analyzer := opts.analyzer(g)
+	root := &shortestNode{paths: 1}
+	nodes := map[string]*shortestNode{g.Key(): root}
+
+	var winners []*shortestNode
+	if g.Won() {
+		winners = append(winners, root)
+	}
+
+	frontier := []enumerated{{g.Clone(), root}}
+	for depth := 0; len(winners) == 0; depth++ {
+		if len(frontier) == 0 {
+			return result, ErrNoSolution
+		}
+
+		var next []enumerated
+		for _, current := range frontier {
+			if result.Expanded%cancelCheckInterval == 0 {
+				if err := ctx.Err(); err != nil {
+					return result, err
+				}
+			}
+			if opts.MaxNodes > 0 && result.Expanded >= opts.MaxNodes {
+				return result, ErrLimit
+			}
+			result.Expanded++
+
+			for _, dir := range Directions {
+				state := current.game.Clone()
+				state.Move(dir)
+
+				key := state.Key()
+				edge := shortestEdge{current.node, dir}
+				if n, ok := nodes[key]; ok {
+					if n.depth == depth+1 {
+						n.parents = append(n.parents, edge)
+						n.paths = min(n.paths+current.node.paths, maxPaths)
+					}
+					continue
+				}
+
+				n := &shortestNode{depth: depth + 1, parents: []shortestEdge{edge}, paths: current.node.paths}
+				nodes[key] = n
+				if state.Won() {
+					winners = append(winners, n)
+					continue
+				}
+				if Lost(state) || prune(analyzer, state, &Result{}) {
+					continue
+				}
+				next = append(next, enumerated{state, n})
+			}
+		}
+		frontier = next
+	}
+
+	result.Length = winners[0].depth
+	for _, w := range winners {
+		result.Total = min(result.Total+w.paths, maxPaths)
+	}
+
+	path := make([]game.Direction, result.Length)
+	var walk func(n *shortestNode) bool
+	walk = func(n *shortestNode) bool {
+		if n.depth == 0 {
+			solution := make([]game.Direction, len(path))
+			copy(solution, path)
+			result.Solutions = append(result.Solutions, solution)
+			return limit == 0 || len(result.Solutions) < limit
+		}
+		for _, edge := range n.parents {
+			path[n.depth-1] = edge.dir
+			if !walk(edge.parent) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, w := range winners {
+		if !walk(w) {
+			break
+		}
+	}
+	return result, nil
+}
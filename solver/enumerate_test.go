@@ -0,0 +1,87 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"slimesolver/game"
+	"testing"
+)
+
+func TestEnumerate(t *testing.T) {
+	tt := []struct {
+		name   string
+		state  string
+		limit  int
+		length int
+		total  int
+		want   int
+	}{
+		{
+			name: "two ways",
+			state: `@.
+					.*`,
+			length: 2,
+			total:  2,
+			want:   2,
+		},
+		{
+			name: "open room",
+			state: `@..
+					...
+					..*`,
+			length: 4,
+			total:  6,
+			want:   6,
+		},
+		{
+			name: "limited",
+			state: `@..
+					...
+					..*`,
+			limit:  4,
+			length: 4,
+			total:  6,
+			want:   4,
+		},
+		{
+			name:   "one way",
+			state:  `@BO.*`,
+			length: 4,
+			total:  1,
+			want:   1,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := Enumerate(context.Background(), newGame(t, tc.state), tc.limit, Options{Prune: true})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Length != tc.length || result.Total != tc.total || len(result.Solutions) != tc.want {
+				t.Fatalf("expected %d of %d solutions of %d moves, got %d of %d of %d moves", tc.want, tc.total, tc.length,
+					len(result.Solutions), result.Total, result.Length)
+			}
+
+			seen := make(map[string]bool)
+			for _, moves := range result.Solutions {
+				s := game.FormatMoves(moves)
+				if seen[s] {
+					t.Fatalf("expected distinct solutions, got %s twice", s)
+				}
+				seen[s] = true
+				if len(moves) != tc.length {
+					t.Fatalf("expected %d moves, got %s", tc.length, s)
+				}
+				replay(t, tc.state, moves)
+			}
+		})
+	}
+}
+
+func TestEnumerateNoSolution(t *testing.T) {
+	_, err := Enumerate(context.Background(), newGame(t, `@O*`), 0, Options{})
+	if !errors.Is(err, ErrNoSolution) {
+		t.Fatalf("expected no solution, got %v", err)
+	}
+}
@@ -0,0 +1,360 @@
+package solver
+
+import (
+	"fmt"
+	"slimesolver/game"
+	"slimesolver/game/math"
+)
+
+// Unreachable is estimated for states that can never be won, they are not searched further.
+const Unreachable = 1 << 30
+
+// Estimator guesses how many moves are left to win from g.
+type Estimator func(g *game.Game) int
+
+// Heuristic guides the informed searches.
+type Heuristic struct {
+	Name string
+	// Admissible heuristics never overestimate, searches using them find the shortest solution
+	Admissible bool
+	// New prepares an estimator for states reachable from start
+	New func(start *game.Game) Estimator
+}
+
+var (
+	// Zero estimates nothing, A* with it searches like BFS
+	Zero = Heuristic{
+		Name:       "zero",
+		Admissible: true,
+		New: func(start *game.Game) Estimator {
+			return func(g *game.Game) int { return 0 }
+		},
+	}
+	// Manhattan is the furthest any goal is from its nearest slime, ignoring walls.
+	// A slide on ice can go any distance in one move, so on levels with ice it only tells whether a goal is left.
+	Manhattan = Heuristic{
+		Name:       "manhattan",
+		Admissible: true,
+		New:        newManhattan,
+	}
+	// Distance is Manhattan but walking around walls
+	Distance = Heuristic{
+		Name:       "distance",
+		Admissible: true,
+		New:        newDistance,
+	}
+	// Pits adds the number of pits boxes have to fill before a slime can reach each goal.
+	// Levels without enough boxes are cut off early. It is Distance when pits don't kill slimes.
+	Pits = Heuristic{
+		Name: "pits",
+		New:  newPits,
+	}
+	// Switches adds the number of doors a switch has to open before a slime can reach each goal
+	Switches = Heuristic{
+		Name: "switches",
+		New:  newSwitches,
+	}
+)
+
+// Heuristics lists every heuristic by name.
+var Heuristics = []Heuristic{Zero, Manhattan, Distance, Pits, Switches}
+
+// HeuristicByName returns the heuristic called name.
+func HeuristicByName(name string) (Heuristic, error) {
+	for _, h := range Heuristics {
+		if h.Name == name {
+			return h, nil
+		}
+	}
+	return Heuristic{}, fmt.Errorf("unknown heuristic: %s", name)
+}
+
+func goals(g *game.Game) []math.Vector2 {
+	var l []math.Vector2
+	for y := 0; y < g.Height(); y++ {
+		for x := 0; x < g.Width(); x++ {
+			if g.IsGoal(x, y) {
+				l = append(l, math.Vector2{X: x, Y: y})
+			}
+		}
+	}
+	return l
+}
+
+func slimes(g *game.Game) []math.Vector2 {
+	var l []math.Vector2
+	for _, actor := range g.Actors() {
+		if game.IsSlimeToken(actor.Token()) {
+			l = append(l, actor.GetPosition())
+		}
+	}
+	return l
+}
+
+func countTokens(g *game.Game, tokens ...game.Token) int {
+	return len(g.GetActorsWithTokens(tokens))
+}
+
+// reach is how many tiles slimes can spread in one move. A conveyor can carry a slime
+// one more tile after it moved, and a spike can split it onto a tile next to it after that.
+func reach(start *game.Game) int {
+	tiles := 1
+	if countTokens(start, game.SpikeUpToken, game.SpikeDownToken) > 0 {
+		tiles++
+	}
+	for y := 0; y < start.Height(); y++ {
+		for x := 0; x < start.Width(); x++ {
+			if _, ok := start.Conveyor(x, y); ok {
+				return tiles + 1
+			}
+		}
+	}
+	return tiles
+}
+
+// stepsLeft turns the distance of the slime furthest behind into moves
+// for slimes that spread tiles per move.
+func stepsLeft(distance, tiles int) int {
+	if distance >= Unreachable {
+		return Unreachable
+	}
+	return (distance + tiles - 1) / tiles
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func manhattan(a, b math.Vector2) int {
+	return abs(a.X-b.X) + abs(a.Y-b.Y)
+}
+
+// teleporters returns every pad of start.
+func teleporters(start *game.Game) []math.Vector2 {
+	var l []math.Vector2
+	for y := 0; y < start.Height(); y++ {
+		for x := 0; x < start.Width(); x++ {
+			if start.IsTeleporter(x, y) {
+				l = append(l, math.Vector2{X: x, Y: y})
+			}
+		}
+	}
+	return l
+}
+
+func newManhattan(start *game.Game) Estimator {
+	targets := goals(start)
+	tiles := reach(start)
+	ice := false
+	for y := 0; y < start.Height(); y++ {
+		for x := 0; x < start.Width(); x++ {
+			ice = ice || start.IsIce(x, y)
+		}
+	}
+
+	// a slime that teleports walks at least to the nearest pad and from the nearest pad to the goal
+	pads := teleporters(start)
+	toPad := func(pos math.Vector2) int {
+		nearest := Unreachable
+		for _, pad := range pads {
+			nearest = min(nearest, manhattan(pos, pad))
+		}
+		return nearest
+	}
+	goalToPad := make([]int, len(targets))
+	for i, goal := range targets {
+		goalToPad[i] = toPad(goal)
+	}
+
+	return func(g *game.Game) int {
+		positions := slimes(g)
+		if len(positions) == 0 || len(targets) == 0 {
+			return Unreachable
+		}
+
+		furthest := 0
+		for i, goal := range targets {
+			nearest := Unreachable
+			for _, pos := range positions {
+				nearest = min(nearest, manhattan(goal, pos))
+				if len(pads) > 0 {
+					nearest = min(nearest, toPad(pos)+goalToPad[i])
+				}
+			}
+			furthest = max(furthest, nearest)
+		}
+		if ice {
+			return min(furthest, 1)
+		}
+		return stepsLeft(furthest, tiles)
+	}
+}
+
+// costMap is the cost of reaching a goal from every tile, indexed by y and x.
+type costMap [][]int
+
+// newCostMap runs a 0-1 BFS out from goal over every tile that isn't a wall.
+// cost returns what entering a tile costs, either 0 or 1.
+func newCostMap(g *game.Game, goal math.Vector2, cost func(x, y int) int) costMap {
+	m := make(costMap, g.Height())
+	for y := range m {
+		m[y] = make([]int, g.Width())
+		for x := range m[y] {
+			m[y][x] = Unreachable
+		}
+	}
+
+	m[goal.Y][goal.X] = 0
+	deque := []math.Vector2{goal}
+	for len(deque) > 0 {
+		pos := deque[0]
+		deque = deque[1:]
+
+		// entering a pad can put a slime on its partner for free, it costs at most what the partner costs
+		if partner, ok := g.Partner(pos); ok && m[pos.Y][pos.X] < m[partner.Y][partner.X] {
+			m[partner.Y][partner.X] = m[pos.Y][pos.X]
+			deque = append([]math.Vector2{partner}, deque...)
+		}
+
+		for _, dir := range Directions {
+			next := step(pos, dir)
+			// gates are only entered along their arrow
+			if g.IsWallOrEdge(next.X, next.Y) || g.IsBlocked(pos, opposite(dir)) {
+				continue
+			}
+
+			// walking from next onto pos costs what entering pos costs,
+			// sliding on from ice is free since it happens in the same move
+			enter := cost(pos.X, pos.Y)
+			if g.IsIce(next.X, next.Y) {
+				enter = 0
+			}
+			c := m[pos.Y][pos.X] + enter
+			if c >= m[next.Y][next.X] {
+				continue
+			}
+			m[next.Y][next.X] = c
+			if enter == 0 {
+				deque = append([]math.Vector2{next}, deque...)
+			} else {
+				deque = append(deque, next)
+			}
+		}
+	}
+	return m
+}
+
+func step(pos math.Vector2, dir game.Direction) math.Vector2 {
+	switch dir {
+	case game.Up:
+		pos.Y--
+	case game.Down:
+		pos.Y++
+	case game.Left:
+		pos.X--
+	case game.Right:
+		pos.X++
+	}
+	return pos
+}
+
+// worst returns the highest cost over all goals of reaching them from the nearest slime.
+func worst(maps []costMap, positions []math.Vector2) int {
+	if len(positions) == 0 || len(maps) == 0 {
+		return Unreachable
+	}
+
+	furthest := 0
+	for _, m := range maps {
+		nearest := Unreachable
+		for _, pos := range positions {
+			nearest = min(nearest, m[pos.Y][pos.X])
+		}
+		furthest = max(furthest, nearest)
+	}
+	return furthest
+}
+
+// wallDistances maps the walking distance to every goal of start, walls never change.
+func wallDistances(start *game.Game) []costMap {
+	var maps []costMap
+	for _, goal := range goals(start) {
+		maps = append(maps, newCostMap(start, goal, func(x, y int) int { return 1 }))
+	}
+	return maps
+}
+
+func newDistance(start *game.Game) Estimator {
+	maps := wallDistances(start)
+	tiles := reach(start)
+	return func(g *game.Game) int {
+		return stepsLeft(worst(maps, slimes(g)), tiles)
+	}
+}
+
+func newPits(start *game.Game) Estimator {
+	distance := newDistance(start)
+	if !start.Rules().PitsKill {
+		return distance
+	}
+	targets := goals(start)
+	return func(g *game.Game) int {
+		moves := distance(g)
+		if moves >= Unreachable {
+			return Unreachable
+		}
+
+		// pits get filled so the map is made for every state
+		var maps []costMap
+		for _, goal := range targets {
+			maps = append(maps, newCostMap(g, goal, func(x, y int) int {
+				if g.IsPit(x, y) {
+					return 1
+				}
+				return 0
+			}))
+		}
+
+		pits := worst(maps, slimes(g))
+		if pits > countTokens(g, game.BoxToken, game.LightBoxToken, game.HeavyBoxToken) {
+			return Unreachable
+		}
+		return moves + pits
+	}
+}
+
+func newSwitches(start *game.Game) Estimator {
+	distance := newDistance(start)
+
+	// doors never move
+	doors := make(map[math.Vector2]bool)
+	for _, door := range start.GetActorsWithTokens([]game.Token{game.ClosedDoorToken, game.OpenDoorToken}) {
+		doors[door.GetPosition()] = true
+	}
+	var maps []costMap
+	for _, goal := range goals(start) {
+		maps = append(maps, newCostMap(start, goal, func(x, y int) int {
+			if doors[math.Vector2{X: x, Y: y}] {
+				return 1
+			}
+			return 0
+		}))
+	}
+
+	hasSwitch := countTokens(start, game.SwitchToken) > 0
+	return func(g *game.Game) int {
+		moves := distance(g)
+		if moves >= Unreachable {
+			return Unreachable
+		}
+
+		closed := worst(maps, slimes(g))
+		if closed > 0 && !hasSwitch {
+			return Unreachable
+		}
+		return moves + closed
+	}
+}
@@ -0,0 +1,110 @@
+package solver
+
+import (
+	"testing"
+)
+
+func TestHeuristics(t *testing.T) {
+	tt := []struct {
+		name  string
+		state string
+		// estimate of each heuristic by name
+		want map[string]int
+	}{
+		{
+			name:  "straight line",
+			state: `@..*`,
+			want:  map[string]int{"zero": 0, "manhattan": 3, "distance": 3, "pits": 3, "switches": 3},
+		},
+		{
+			name: "around a wall",
+			state: `@#*
+					...`,
+			want: map[string]int{"manhattan": 2, "distance": 4},
+		},
+		{
+			name: "furthest goal",
+			state: `*@..*
+					.....`,
+			want: map[string]int{"manhattan": 3, "distance": 3},
+		},
+		{
+			name:  "spikes spread slimes",
+			state: `@-...*`,
+			want:  map[string]int{"manhattan": 3, "distance": 3},
+		},
+		{
+			name:  "pit to fill",
+			state: `@BO.*`,
+			want:  map[string]int{"distance": 4, "pits": 5},
+		},
+		{
+			name:  "pit without box",
+			state: `@O*`,
+			want:  map[string]int{"distance": 2, "pits": Unreachable},
+		},
+		{
+			name: "door on the way",
+			state: `@Dx
+					..*`,
+			want: map[string]int{"distance": 3, "switches": 3},
+		},
+		{
+			name:  "door without switch",
+			state: `@D*`,
+			want:  map[string]int{"distance": 2, "switches": Unreachable},
+		},
+		{
+			name:  "teleporter",
+			state: `@1#1*`,
+			want:  map[string]int{"manhattan": 2, "distance": 2},
+		},
+		{
+			name:  "ice",
+			state: `@~~~*`,
+			want:  map[string]int{"manhattan": 1, "distance": 1},
+		},
+		{
+			name:  "conveyor",
+			state: `@>>>*`,
+			want:  map[string]int{"manhattan": 2, "distance": 2},
+		},
+		{
+			name: "gate",
+			state: `@l*
+					...`,
+			want: map[string]int{"manhattan": 2, "distance": 4},
+		},
+		{
+			name:  "no goals",
+			state: `@..`,
+			want:  map[string]int{"manhattan": Unreachable, "distance": Unreachable},
+		},
+		{
+			name:  "walled off goal",
+			state: `@#*`,
+			want:  map[string]int{"distance": Unreachable},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			g := newGame(t, tc.state)
+			for name, want := range tc.want {
+				h, err := HeuristicByName(name)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if got := h.New(g)(g); got != want {
+					t.Fatalf("expected %s to estimate %d, got %d", name, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestHeuristicByName(t *testing.T) {
+	if _, err := HeuristicByName("euclid"); err == nil {
+		t.Fatalf("expected an error")
+	}
+}
@@ -0,0 +1,137 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"slimesolver/game"
+	"time"
+)
+
+// Confidence says how much a Suggestion can be trusted.
+type Confidence int
+
+const (
+	// Guess is suggested when the budget ran out, the move only gets a slime closer to a goal
+	Guess Confidence = iota
+	// Proven moves start a shortest solution
+	Proven
+	// Unwinnable states have no solution, nothing is suggested
+	Unwinnable
+)
+
+var confidenceNames = map[Confidence]string{
+	Guess:      "guess",
+	Proven:     "proven",
+	Unwinnable: "unwinnable",
+}
+
+func (c Confidence) String() string {
+	return confidenceNames[c]
+}
+
+// UndoUnknown is the Undo of an Unwinnable Suggestion whose budget ran out
+// before a state that can still be won was found.
+const UndoUnknown = -1
+
+// Budget bounds the search behind a hint, zero values mean no limit.
+type Budget struct {
+	Time  time.Duration
+	Nodes int
+}
+
+// Suggestion is the next move towards solving a level.
+type Suggestion struct {
+	// Direction is Zero when the level is won or can't be won
+	Direction game.Direction
+	// MovesLeft is the length of the shortest solution, it is only known when Proven
+	MovesLeft  int
+	Confidence Confidence
+	// Undo is how many moves made with Play have to be undone to get back to a state
+	// that can still be won, 0 when it is Unwinnable since the start and
+	// UndoUnknown when the budget ran out before one was found
+	Undo int
+}
+
+// Hint suggests the next move from the current state of g within budget,
+// it guesses as well when ctx is done before the search is. g is not modified.
+func Hint(ctx context.Context, g *game.Game, budget Budget) Suggestion {
+	if g.Won() {
+		return Suggestion{Confidence: Proven}
+	}
+
+	if budget.Time > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, budget.Time)
+		defer cancel()
+	}
+
+	opts := Options{Algorithm: AStar, Heuristic: Distance, Prune: true, MaxNodes: budget.Nodes}
+	result, err := Solve(ctx, g, opts)
+	switch {
+	case err == nil:
+		return Suggestion{
+			Direction:  result.Moves[0],
+			MovesLeft:  len(result.Moves),
+			Confidence: Proven,
+		}
+	case errors.Is(err, ErrNoSolution):
+		undo, ok := undoDistance(ctx, g, opts, result.Expanded)
+		if !ok {
+			undo = UndoUnknown
+		}
+		return Suggestion{Confidence: Unwinnable, Undo: undo}
+	}
+	return guess(g)
+}
+
+// undoDistance searches back through the history of g for the last state that could be won.
+// It returns 0 when no state in the history can be won and false when the budget ran out first.
+func undoDistance(ctx context.Context, g *game.Game, opts Options, expanded int) (int, bool) {
+	budget := opts.MaxNodes
+	for n := 1; ; n++ {
+		previous := g.Previous(n)
+		if previous == nil {
+			return 0, true
+		}
+
+		if budget > 0 {
+			opts.MaxNodes = budget - expanded
+			if opts.MaxNodes <= 0 {
+				return 0, false
+			}
+		}
+		result, err := Solve(ctx, previous, opts)
+		expanded += result.Expanded
+		switch {
+		case err == nil:
+			return n, true
+		case !errors.Is(err, ErrNoSolution):
+			return 0, false
+		}
+	}
+}
+
+// guess picks the move that brings slimes closest to the goals without losing.
+func guess(g *game.Game) Suggestion {
+	estimate := Distance.New(g)
+	analyzer := NewAnalyzer(g)
+	key := g.Key()
+
+	best := Suggestion{Confidence: Guess}
+	bestCost := Unreachable
+	for _, dir := range Directions {
+		next := g.Clone()
+		next.Move(dir)
+		if Lost(next) || next.Key() == key {
+			continue
+		}
+		if _, dead := analyzer.Check(next); dead {
+			continue
+		}
+		if cost := estimate(next); cost < bestCost {
+			best.Direction = dir
+			bestCost = cost
+		}
+	}
+	return best
+}
@@ -0,0 +1,79 @@
+package solver
+
+import (
+	"context"
+	"slimesolver/game"
+	"testing"
+)
+
+func TestHint(t *testing.T) {
+	g := newGame(t, `@BO.*`)
+	hint := Hint(context.Background(), g, Budget{})
+	if hint.Direction != game.Right || hint.MovesLeft != 4 || hint.Confidence != Proven {
+		t.Fatalf("unexpected hint: %+v", hint)
+	}
+
+	g.Play(game.Right)
+	g.Play(game.Right)
+	g.Play(game.Right)
+	hint = Hint(context.Background(), g, Budget{})
+	if hint.Direction != game.Right || hint.MovesLeft != 1 || hint.Confidence != Proven {
+		t.Fatalf("unexpected hint: %+v", hint)
+	}
+
+	g.Play(game.Right)
+	hint = Hint(context.Background(), g, Budget{})
+	if hint.Direction != game.Zero || hint.Confidence != Proven {
+		t.Fatalf("expected nothing left to do, got %+v", hint)
+	}
+}
+
+func TestHintUnwinnable(t *testing.T) {
+	// pushing the box left sticks it against the edge
+	g := newGame(t, `....#
+					 .B@O*`)
+	g.Play(game.Up)
+	g.Play(game.Down)
+	g.Play(game.Left)
+	g.Play(game.Up)
+
+	hint := Hint(context.Background(), g, Budget{})
+	if hint.Direction != game.Zero || hint.Confidence != Unwinnable || hint.Undo != 2 {
+		t.Fatalf("expected to undo 2 moves, got %+v", hint)
+	}
+
+	// the budget runs out searching the history
+	hint = Hint(context.Background(), g, Budget{Nodes: 2})
+	if hint.Confidence != Unwinnable || hint.Undo != UndoUnknown {
+		t.Fatalf("expected an unknown number of moves to undo, got %+v", hint)
+	}
+
+	hint = Hint(context.Background(), newGame(t, `@O*`), Budget{})
+	if hint.Confidence != Unwinnable || hint.Undo != 0 {
+		t.Fatalf("expected unwinnable from the start, got %+v", hint)
+	}
+}
+
+func TestHintBudget(t *testing.T) {
+	g := newGame(t, `.........
+					 @#......*
+					 .........`)
+	hint := Hint(context.Background(), g, Budget{Nodes: 2})
+	if hint.Direction == game.Zero || hint.Confidence != Guess {
+		t.Fatalf("expected a guess, got %+v", hint)
+	}
+
+	g.Play(hint.Direction)
+	if Lost(g) {
+		t.Fatalf("expected the guess to keep the slime alive")
+	}
+}
+
+func TestHintCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	hint := Hint(ctx, newGame(t, `@BO.*`), Budget{})
+	if hint.Direction != game.Right || hint.Confidence != Guess {
+		t.Fatalf("expected a guess once the context is done, got %+v", hint)
+	}
+}
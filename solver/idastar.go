@@ -0,0 +1,99 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"slimesolver/game"
+)
+
+// errFound unwinds the search once a solution is found
+var errFound = errors.New("found")
+
+type idaSearch struct {
+	ctx      context.Context
+	opts     Options
+	estimate Estimator
+	analyzer *Analyzer
+	result   Result
+
+	// states on the current path, they are not entered again
+	path  map[string]bool
+	moves []game.Direction
+}
+
+func solveIDAStar(ctx context.Context, g *game.Game, opts Options) (Result, error) {
+	h := opts.heuristic()
+	s := &idaSearch{
+		ctx:      ctx,
+		opts:     opts,
+		estimate: h.New(g),
+		analyzer: opts.analyzer(g),
+		path:     make(map[string]bool),
+	}
+
+	start := g.Clone()
+	bound := s.estimate(start)
+	for bound < Unreachable {
+		next, err := s.search(start, start.Key(), bound)
+		if errors.Is(err, errFound) {
+			s.result.Moves = s.moves
+			s.result.Optimal = h.Admissible
+			return s.result, nil
+		}
+		if err != nil {
+			return s.result, err
+		}
+		bound = next
+	}
+	return s.result, ErrNoSolution
+}
+
+// search looks for a win within bound moves of the start, counting the estimate of the last state.
+// It returns the smallest cost over the bound it saw, which is the bound of the next iteration.
+func (s *idaSearch) search(g *game.Game, key string, bound int) (int, error) {
+	s.result.Visited++
+	depth := len(s.moves)
+	cost := depth + s.estimate(g)
+	if cost > bound {
+		return cost, nil
+	}
+	if g.Won() {
+		return cost, errFound
+	}
+
+	if s.result.Expanded%cancelCheckInterval == 0 {
+		if err := s.ctx.Err(); err != nil {
+			return 0, err
+		}
+	}
+	if s.opts.MaxNodes > 0 && s.result.Expanded >= s.opts.MaxNodes {
+		return 0, ErrLimit
+	}
+	s.result.Expanded++
+
+	s.path[key] = true
+	s.result.Peak = max(s.result.Peak, len(s.path))
+	defer delete(s.path, key)
+
+	smallest := Unreachable
+	for _, dir := range Directions {
+		next := g.Clone()
+		next.Move(dir)
+		if Lost(next) {
+			continue
+		}
+		nextKey := next.Key()
+		if s.path[nextKey] || prune(s.analyzer, next, &s.result) {
+			continue
+		}
+
+		s.moves = append(s.moves, dir)
+		c, err := s.search(next, nextKey, bound)
+		if err != nil {
+			return c, err
+		}
+		s.moves = s.moves[:depth]
+		smallest = min(smallest, c)
+	}
+	return smallest, nil
+}
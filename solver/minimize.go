@@ -0,0 +1,147 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"slimesolver/game"
+)
+
+// ErrNotSolution is returned when a replay doesn't win the level.
+var ErrNotSolution = errors.New("moves don't solve the level")
+
+// Minimized is a shorter replay and where the moves went.
+type Minimized struct {
+	Moves []game.Direction
+	// NoOps counts moves that didn't change the board and weren't needed
+	NoOps int
+	// Loops counts moves between two visits of the same state
+	Loops int
+	// Shortcuts counts moves saved by searching again from a state of the replay
+	Shortcuts int
+	// Expanded is the number of states expanded by all the searches together
+	Expanded int
+}
+
+// replayStates returns the state before every move and after the last one.
+func replayStates(g *game.Game, moves []game.Direction) []*game.Game {
+	states := make([]*game.Game, 0, len(moves)+1)
+	current := g.Clone()
+	states = append(states, current)
+	for _, dir := range moves {
+		current = current.Clone()
+		current.Move(dir)
+		states = append(states, current)
+	}
+	return states
+}
+
+// wins replays moves from g and returns how many of them it takes to win, or -1.
+func wins(g *game.Game, moves []game.Direction) int {
+	current := g.Clone()
+	if current.Won() {
+		return 0
+	}
+	for i, dir := range moves {
+		current.Move(dir)
+		if current.Won() {
+			return i + 1
+		}
+	}
+	return -1
+}
+
+func without(moves []game.Direction, from, to int) []game.Direction {
+	l := make([]game.Direction, 0, len(moves)-(to-from))
+	l = append(l, moves[:from]...)
+	return append(l, moves[to:]...)
+}
+
+// Minimize shortens a replay that solves g. Moves after the level is won are dropped,
+// then loops back to an earlier state are cut, then moves that left the board as it was
+// are dropped when the level is still won without them. Last it searches for a
+// solution from every state of the replay, starting at the end, and keeps the ones
+// that are shorter than the rest of the replay. Searches use opts, but opts.MaxNodes
+// bounds all of them together, and stop at the first that runs out of nodes or time,
+// searching further back is only harder.
+// g is not modified.
+func Minimize(ctx context.Context, g *game.Game, moves []game.Direction, opts Options) (Minimized, error) {
+	n := wins(g, moves)
+	if n == -1 {
+		return Minimized{}, ErrNotSolution
+	}
+	result := Minimized{Moves: moves[:n]}
+
+	result.Moves, result.Loops = cutLoops(g, result.Moves)
+	result.Moves, result.NoOps = dropNoOps(g, result.Moves)
+
+	// a search from an earlier state can be cut short, what was found so far is kept
+	budget := opts.MaxNodes
+	for i := len(result.Moves) - 1; i >= 0; i-- {
+		if budget > 0 {
+			opts.MaxNodes = budget - result.Expanded
+			if opts.MaxNodes <= 0 {
+				break
+			}
+		}
+		states := replayStates(g, result.Moves[:i])
+		found, err := Solve(ctx, states[i], opts)
+		result.Expanded += found.Expanded
+		if err != nil {
+			if errors.Is(err, ErrNoSolution) {
+				// the replay wins so this only happens when a rule was wrong
+				continue
+			}
+			break
+		}
+		if left := len(result.Moves) - i; len(found.Moves) < left {
+			result.Shortcuts += left - len(found.Moves)
+			result.Moves = append(result.Moves[:i:i], found.Moves...)
+		}
+	}
+	return result, nil
+}
+
+// cutLoops removes the moves between two visits of the same state, the longest loop first.
+func cutLoops(g *game.Game, moves []game.Direction) ([]game.Direction, int) {
+	cut := 0
+	for {
+		states := replayStates(g, moves)
+		first := make(map[string]int)
+		from, to := -1, -1
+		for i, state := range states {
+			key := state.Key()
+			j, ok := first[key]
+			if !ok {
+				first[key] = i
+				continue
+			}
+			if to-from < i-j {
+				from, to = j, i
+			}
+		}
+		if from == -1 {
+			return moves, cut
+		}
+		cut += to - from
+		moves = without(moves, from, to)
+	}
+}
+
+// dropNoOps removes moves after which the board looks the same, if the level is still won without them.
+// Bumping into a wall changes where a slime splits, so a move can look like nothing happened and still matter.
+func dropNoOps(g *game.Game, moves []game.Direction) ([]game.Direction, int) {
+	dropped := 0
+	for i := len(moves) - 1; i >= 0; i-- {
+		states := replayStates(g, moves[:i+1])
+		if states[i].String() != states[i+1].String() {
+			continue
+		}
+		shorter := without(moves, i, i+1)
+		if n := wins(g, shorter); n != -1 {
+			dropped += len(moves) - n
+			moves = shorter[:n]
+			i = min(i, len(moves))
+		}
+	}
+	return moves, dropped
+}
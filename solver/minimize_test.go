@@ -0,0 +1,98 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"slimesolver/game"
+	"testing"
+)
+
+func TestMinimize(t *testing.T) {
+	tt := []struct {
+		name  string
+		state string
+		moves string
+		want  string
+		// moves removed by each step
+		noOps, loops, shortcuts int
+	}{
+		{
+			name:  "already short",
+			state: `@..*`,
+			moves: "rrr",
+			want:  "rrr",
+		},
+		{
+			name:  "moves after winning",
+			state: `@*`,
+			moves: "rlr",
+			want:  "r",
+		},
+		{
+			name:  "loop and bump",
+			state: `@...*`,
+			moves: "rlrurrr",
+			want:  "rrrr",
+			noOps: 1,
+			loops: 2,
+		},
+		{
+			name: "detour",
+			state: `@..
+					...
+					..*`,
+			moves:     "rdlddrr",
+			want:      "rrdd",
+			noOps:     1,
+			shortcuts: 2,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			moves, err := game.ParseMoves(tc.moves)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			result, err := Minimize(context.Background(), newGame(t, tc.state), moves, Options{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(result.Moves) != len(tc.want) {
+				t.Fatalf("expected %s, got %s", tc.want, game.FormatMoves(result.Moves))
+			}
+			if result.NoOps != tc.noOps || result.Loops != tc.loops || result.Shortcuts != tc.shortcuts {
+				t.Fatalf("expected %d no-ops, %d loops and %d shortcuts, got %+v", tc.noOps, tc.loops, tc.shortcuts, result)
+			}
+			replay(t, tc.state, result.Moves)
+		})
+	}
+}
+
+func TestMinimizeNotSolution(t *testing.T) {
+	_, err := Minimize(context.Background(), newGame(t, `@..*`), []game.Direction{game.Right}, Options{})
+	if !errors.Is(err, ErrNotSolution) {
+		t.Fatalf("expected not a solution, got %v", err)
+	}
+}
+
+func TestMinimizeBudget(t *testing.T) {
+	state := `@..
+			  ...
+			  ..*`
+	moves, err := game.ParseMoves("rdlddrr")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// every search shares the budget, so the search from the start doesn't get to run
+	result, err := Minimize(context.Background(), newGame(t, state), moves, Options{MaxNodes: 10})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Expanded > 10 || result.Shortcuts != 0 {
+		t.Fatalf("expected at most 10 states expanded and no shortcuts, got %+v", result)
+	}
+	replay(t, state, result.Moves)
+}
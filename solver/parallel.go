@@ -0,0 +1,194 @@
+package solver
+
+import (
+	"context"
+	"slimesolver/game"
+	"sync"
+	"sync/atomic"
+)
+
+type parallelItem struct {
+	game  *game.Game
+	node  *node
+	key   string
+	entry entry
+}
+
+// child is a state found by a worker, it is only kept if its entry still owns
+// the state in the table once every worker is done.
+type child struct {
+	game    *game.Game
+	key     string
+	entry   entry
+	claimed bool
+	lost    bool
+	rule    Rule
+	cost    int
+}
+
+// solveParallel is AStar that expands every state with the lowest estimate at once,
+// spread over opts.Workers goroutines. BFS is the same search with the Zero heuristic.
+//
+// Workers race to claim states in the table, but a claim is decided by the fewest
+// moves and then by the order states were expanded in, never by which worker was first.
+// Children are merged in that order too, so the result doesn't depend on the number of
+// workers or how they were scheduled.
+func solveParallel(ctx context.Context, g *game.Game, opts Options) (Result, error) {
+	result := Result{}
+	h := opts.heuristic()
+	if opts.Algorithm == BFS {
+		h = Zero
+	}
+	estimate := h.New(g)
+	analyzer := opts.analyzer(g)
+
+	states := newTable()
+	start := g.Clone()
+	startKey := start.Key()
+	states.claim(startKey, entry{})
+
+	// states waiting to be expanded by their estimated solution length
+	open := make(map[int][]*parallelItem)
+	size := 0
+	if cost := estimate(start); cost < Unreachable {
+		open[cost] = []*parallelItem{{start, &node{}, startKey, entry{}}}
+		size++
+	}
+
+	var batch uint64
+	for len(open) > 0 {
+		result.Peak = max(result.Peak, size+states.len())
+
+		cost := Unreachable
+		for c := range open {
+			cost = min(cost, c)
+		}
+		items := open[cost]
+		delete(open, cost)
+		size -= len(items)
+
+		current := items[:0]
+		for _, item := range items {
+			// a shorter way here was found after this was queued
+			if e, _ := states.get(item.key); e == item.entry {
+				current = append(current, item)
+			}
+		}
+
+		for _, item := range current {
+			if item.game.Won() {
+				result.Moves = item.node.moves()
+				result.Visited = states.len()
+				result.Optimal = h.Admissible
+				return result, nil
+			}
+		}
+
+		if err := ctx.Err(); err != nil {
+			result.Visited = states.len()
+			return result, err
+		}
+		if opts.MaxNodes > 0 {
+			left := opts.MaxNodes - result.Expanded
+			if left <= 0 {
+				result.Visited = states.len()
+				return result, ErrLimit
+			}
+			if len(current) > left {
+				open[cost] = current[left:]
+				size += len(current) - left
+				current = current[:left]
+			}
+		}
+
+		batch++
+		children := expandParallel(ctx, current, batch, opts.Workers, states, estimate, analyzer)
+		if err := ctx.Err(); err != nil {
+			result.Visited = states.len()
+			return result, err
+		}
+		result.Expanded += len(current)
+
+		for i, item := range current {
+			for j, c := range children[i] {
+				if !c.claimed || c.lost {
+					continue
+				}
+				if e, _ := states.get(c.key); e != c.entry {
+					continue
+				}
+				if c.rule != "" {
+					if result.Pruned == nil {
+						result.Pruned = make(map[Rule]int)
+					}
+					result.Pruned[c.rule]++
+					continue
+				}
+				if c.cost >= Unreachable {
+					continue
+				}
+
+				n := &node{item.node, Directions[j], c.entry.depth}
+				f := c.entry.depth + c.cost
+				open[f] = append(open[f], &parallelItem{c.game, n, c.key, c.entry})
+				size++
+			}
+		}
+	}
+
+	result.Visited = states.len()
+	return result, ErrNoSolution
+}
+
+// expandParallel tries every direction from every item on workers goroutines.
+func expandParallel(ctx context.Context, items []*parallelItem, batch uint64, workers int,
+	states *table, estimate Estimator, analyzer *Analyzer) [][]child {
+	children := make([][]child, len(items))
+
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for w := 0; w < min(workers, len(items)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(next.Add(1) - 1)
+				if i >= len(items) || ctx.Err() != nil {
+					return
+				}
+				children[i] = expandItem(items[i], batch<<32|uint64(i)<<2, states, estimate, analyzer)
+			}
+		}()
+	}
+	wg.Wait()
+	return children
+}
+
+func expandItem(item *parallelItem, order uint64, states *table, estimate Estimator, analyzer *Analyzer) []child {
+	children := make([]child, len(Directions))
+	for j, dir := range Directions {
+		c := &children[j]
+		c.game = item.game.Clone()
+		c.game.Move(dir)
+		if Lost(c.game) {
+			c.lost = true
+			continue
+		}
+
+		c.key = c.game.Key()
+		c.entry = entry{item.entry.depth + 1, order | uint64(j)}
+		c.claimed = states.claim(c.key, c.entry)
+		if !c.claimed {
+			continue
+		}
+
+		if analyzer != nil {
+			if rule, dead := analyzer.Check(c.game); dead {
+				c.rule = rule
+				continue
+			}
+		}
+		c.cost = estimate(c.game)
+	}
+	return children
+}
@@ -0,0 +1,121 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"slimesolver/game"
+	"slimesolver/levels"
+	"testing"
+	"time"
+)
+
+func TestSolveParallel(t *testing.T) {
+	for _, algorithm := range []Algorithm{BFS, AStar} {
+		for _, tc := range solveCases {
+			t.Run(algorithm.String()+"/"+tc.name, func(t *testing.T) {
+				var first Result
+				for _, workers := range []int{1, 2, 8} {
+					result, err := Solve(context.Background(), newGame(t, tc.state), Options{Algorithm: algorithm, Workers: workers, Prune: true})
+					if tc.length == -1 {
+						if !errors.Is(err, ErrNoSolution) {
+							t.Fatalf("expected no solution, got %v", err)
+						}
+						continue
+					}
+					if err != nil {
+						t.Fatalf("unexpected error: %v", err)
+					}
+					if len(result.Moves) != tc.length || !result.Optimal {
+						t.Fatalf("expected %d moves, got %s", tc.length, game.FormatMoves(result.Moves))
+					}
+					replay(t, tc.state, result.Moves)
+
+					if workers == 1 {
+						first = result
+						continue
+					}
+					if game.FormatMoves(result.Moves) != game.FormatMoves(first.Moves) || result.Expanded != first.Expanded {
+						t.Fatalf("expected the same search with %d workers, got %s after %d, want %s after %d", workers,
+							game.FormatMoves(result.Moves), result.Expanded, game.FormatMoves(first.Moves), first.Expanded)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestSolveParallelDeterministic(t *testing.T) {
+	level, err := levels.Get("classic", "01-the-problem")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var want string
+	for i, workers := range []int{1, 3, 4, 16, 16} {
+		result, err := Solve(context.Background(), newGame(t, level.Data), Options{Algorithm: AStar, Workers: workers})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		moves := game.FormatMoves(result.Moves)
+		if len(result.Moves) != 23 {
+			t.Fatalf("expected 23 moves, got %s", moves)
+		}
+		if i == 0 {
+			want = moves
+		} else if moves != want {
+			t.Fatalf("expected %s with %d workers, got %s", want, workers, moves)
+		}
+	}
+}
+
+func TestSolveParallelLimits(t *testing.T) {
+	state := `@.........
+			  ..........
+			  ..........
+			  .........*`
+
+	result, err := Solve(context.Background(), newGame(t, state), Options{MaxNodes: 5, Workers: 4})
+	if !errors.Is(err, ErrLimit) || result.Expanded != 5 {
+		t.Fatalf("expected limit error after 5 states, got %v after %d", err, result.Expanded)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+	_, err = Solve(ctx, newGame(t, state), Options{Workers: 4})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline error, got %v", err)
+	}
+}
+
+func benchmarkSolve(b *testing.B, pack, name string, opts Options) {
+	level, err := levels.Get(pack, name)
+	if err != nil {
+		b.Fatalf("unexpected error: %v", err)
+	}
+	g := newGame(b, level.Data)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Solve(context.Background(), g, opts); err != nil {
+			b.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+func BenchmarkBFS(b *testing.B) {
+	benchmarkSolve(b, "tutorial", "05-together", Options{})
+}
+
+func BenchmarkBFSParallel(b *testing.B) {
+	benchmarkSolve(b, "tutorial", "05-together", Options{Workers: runtime.GOMAXPROCS(0)})
+}
+
+func BenchmarkAStar(b *testing.B) {
+	benchmarkSolve(b, "classic", "01-the-problem", Options{Algorithm: AStar})
+}
+
+func BenchmarkAStarParallel(b *testing.B) {
+	benchmarkSolve(b, "classic", "01-the-problem", Options{Algorithm: AStar, Workers: runtime.GOMAXPROCS(0)})
+}
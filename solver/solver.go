@@ -0,0 +1,217 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slimesolver/game"
+)
+
+var (
+	// ErrNoSolution is returned when every reachable state was searched without winning
+	ErrNoSolution = errors.New("no solution")
+	// ErrLimit is returned when the search stopped at Options.MaxNodes
+	ErrLimit = errors.New("search limit reached")
+)
+
+// Directions are tried in this order when expanding a state.
+var Directions = []game.Direction{game.Up, game.Down, game.Left, game.Right}
+
+// how many states are expanded between checks of the context
+const cancelCheckInterval = 256
+
+// Algorithm is the search used by Solve.
+type Algorithm int
+
+const (
+	// BFS tries every state by distance from the start, it always finds the shortest solution
+	BFS Algorithm = iota
+	// AStar tries the states the heuristic thinks are closest to winning first
+	AStar
+	// IDAStar is AStar that only keeps the current path in memory,
+	// it repeats work to search levels too large to remember every state of
+	IDAStar
+)
+
+var algorithmNames = map[Algorithm]string{
+	BFS:     "bfs",
+	AStar:   "astar",
+	IDAStar: "idastar",
+}
+
+func (a Algorithm) String() string {
+	return algorithmNames[a]
+}
+
+// ParseAlgorithm returns the algorithm named bfs, astar or idastar.
+func ParseAlgorithm(name string) (Algorithm, error) {
+	for a, n := range algorithmNames {
+		if n == name {
+			return a, nil
+		}
+	}
+	return BFS, fmt.Errorf("unknown algorithm: %s", name)
+}
+
+type Options struct {
+	// MaxNodes stops the search after expanding this many states, 0 means no limit
+	MaxNodes  int
+	Algorithm Algorithm
+	// Heuristic guides AStar and IDAStar, Distance is used when it is not set
+	Heuristic Heuristic
+	// Prune skips states the deadlock Analyzer proves can never be won
+	Prune bool
+	// Workers is how many goroutines BFS and AStar expand states on, 0 searches on the calling goroutine.
+	// Any number of workers finds the same solution. IDAStar always uses one.
+	Workers int
+}
+
+type Result struct {
+	// Moves solves the level when applied with Move from the starting state
+	Moves []game.Direction
+	// Expanded is the number of states whose moves were tried
+	Expanded int
+	// Visited is the number of distinct states seen, IDAStar counts repeats
+	Visited int
+	// Peak is the most states held in memory at once, both queued and remembered as seen.
+	// IDAStar only holds its current path.
+	Peak int
+	// Optimal is set when Moves is proven to be the shortest solution
+	Optimal bool
+	// Pruned counts the states skipped by each deadlock rule when Options.Prune is set
+	Pruned map[Rule]int
+}
+
+type node struct {
+	parent *node
+	move   game.Direction
+	depth  int
+}
+
+func (n *node) moves() []game.Direction {
+	moves := make([]game.Direction, n.depth)
+	for ; n.parent != nil; n = n.parent {
+		moves[n.depth-1] = n.move
+	}
+	return moves
+}
+
+type queued struct {
+	game *game.Game
+	node *node
+}
+
+// Solve searches Move from the state of g with opts.Algorithm and returns a solution.
+// g is not modified.
+// The search stops with the context's error when ctx is done.
+func Solve(ctx context.Context, g *game.Game, opts Options) (Result, error) {
+	if opts.Workers > 0 && opts.Algorithm != IDAStar {
+		return solveParallel(ctx, g, opts)
+	}
+
+	switch opts.Algorithm {
+	case AStar:
+		return solveAStar(ctx, g, opts)
+	case IDAStar:
+		return solveIDAStar(ctx, g, opts)
+	}
+	return solveBFS(ctx, g, opts)
+}
+
+func (opts Options) heuristic() Heuristic {
+	if opts.Heuristic.New == nil {
+		return Distance
+	}
+	return opts.Heuristic
+}
+
+func (opts Options) analyzer(start *game.Game) *Analyzer {
+	if !opts.Prune {
+		return nil
+	}
+	return NewAnalyzer(start)
+}
+
+// prune reports whether a proves g can never be won and counts the rule that did.
+func prune(a *Analyzer, g *game.Game, result *Result) bool {
+	if a == nil {
+		return false
+	}
+	rule, dead := a.Check(g)
+	if !dead {
+		return false
+	}
+	if result.Pruned == nil {
+		result.Pruned = make(map[Rule]int)
+	}
+	result.Pruned[rule]++
+	return true
+}
+
+func solveBFS(ctx context.Context, g *game.Game, opts Options) (Result, error) {
+	result := Result{}
+	start := g.Clone()
+	analyzer := opts.analyzer(start)
+	root := &node{}
+	if start.Won() {
+		result.Moves = root.moves()
+		result.Optimal = true
+		return result, nil
+	}
+
+	visited := map[string]bool{start.Key(): true}
+	queue := []queued{{start, root}}
+	for len(queue) > 0 {
+		if result.Expanded%cancelCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				result.Visited = len(visited)
+				return result, err
+			}
+		}
+		if opts.MaxNodes > 0 && result.Expanded >= opts.MaxNodes {
+			result.Visited = len(visited)
+			return result, ErrLimit
+		}
+
+		result.Peak = max(result.Peak, len(queue)+len(visited))
+		current := queue[0]
+		queue = queue[1:]
+		result.Expanded++
+
+		for _, dir := range Directions {
+			next := current.game.Clone()
+			next.Move(dir)
+
+			key := next.Key()
+			if visited[key] {
+				continue
+			}
+			visited[key] = true
+
+			child := &node{current.node, dir, current.node.depth + 1}
+			if next.Won() {
+				result.Moves = child.moves()
+				result.Visited = len(visited)
+				result.Optimal = true
+				return result, nil
+			}
+			if Lost(next) || prune(analyzer, next, &result) {
+				continue
+			}
+			queue = append(queue, queued{next, child})
+		}
+	}
+
+	result.Visited = len(visited)
+	return result, ErrNoSolution
+}
+
+// Lost reports whether g can never be won because every slime is gone.
+func Lost(g *game.Game) bool {
+	for _, actor := range g.Actors() {
+		if game.IsSlimeToken(actor.Token()) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,234 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"slimesolver/game"
+	"slimesolver/levels"
+	"testing"
+	"time"
+)
+
+type solveCase struct {
+	name  string
+	state string
+	// length of the shortest solution, -1 when there is none
+	length int
+}
+
+func newGame(t testing.TB, state string) *game.Game {
+	t.Helper()
+	g := game.NewGame(nil)
+	if err := g.Parse(state); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return g
+}
+
+// replay checks that moves win the level from its starting state
+func replay(t testing.TB, state string, moves []game.Direction) {
+	t.Helper()
+	g := newGame(t, state)
+	for _, dir := range moves {
+		g.Move(dir)
+	}
+	if !g.Won() {
+		t.Fatalf("expected %s to win\n%s", game.FormatMoves(moves), g.String())
+	}
+}
+
+var solveCases = []solveCase{
+	{
+		name:   "no slimes",
+		state:  `#*#`,
+		length: -1,
+	},
+	{
+		name:   "walk to goal",
+		state:  `@..*`,
+		length: 3,
+	},
+	{
+		name: "walk around wall",
+		state: `@#*
+				...`,
+		length: 4,
+	},
+	{
+		name:   "fill pit with box",
+		state:  `@BO.*`,
+		length: 4,
+	},
+	{
+		name: "open door with box",
+		state: `....
+				.B.x
+				@.#D
+				..#*`,
+		length: 6,
+	},
+	{
+		name:   "walled in",
+		state:  `@#*`,
+		length: -1,
+	},
+	{
+		name:   "pit without box",
+		state:  `@O*`,
+		length: -1,
+	},
+	{
+		name: "stop on a goal past the ice",
+		state: `@~~~#
+				#~~.*`,
+		length: 3,
+	},
+	{
+		name:   "teleport past a wall",
+		state:  `@1#1*`,
+		length: 2,
+	},
+	{
+		name:   "ride a conveyor",
+		state:  `@>>>*`,
+		length: 2,
+	},
+	{
+		name:   "unlock a door",
+		state:  `@kL*`,
+		length: 3,
+	},
+	{
+		name: "walk around a gate",
+		state: `@l*
+				...`,
+		length: 4,
+	},
+	{
+		name: "carry a box into a pit",
+		state: `.@.
+				#B#
+				#>O
+				##*`,
+		length: 3,
+	},
+}
+
+func TestSolve(t *testing.T) {
+	for _, tc := range solveCases {
+		t.Run(tc.name, func(t *testing.T) {
+			state := tc.state
+			g := newGame(t, state)
+			result, err := Solve(context.Background(), g, Options{})
+			if tc.length == -1 {
+				if !errors.Is(err, ErrNoSolution) {
+					t.Fatalf("expected no solution, got %v %s", err, game.FormatMoves(result.Moves))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(result.Moves) != tc.length {
+				t.Fatalf("expected %d moves, got %s", tc.length, game.FormatMoves(result.Moves))
+			}
+			replay(t, state, result.Moves)
+		})
+	}
+}
+
+func TestSolveLimits(t *testing.T) {
+	state := `@.........
+			  ..........
+			  ..........
+			  .........*`
+
+	_, err := Solve(context.Background(), newGame(t, state), Options{MaxNodes: 5})
+	if !errors.Is(err, ErrLimit) {
+		t.Fatalf("expected limit error, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+	_, err = Solve(ctx, newGame(t, state), Options{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline error, got %v", err)
+	}
+}
+
+func TestSolvePeak(t *testing.T) {
+	// the goal is walled off, so every state is remembered by the end of the search
+	state := `#####.
+			  #@..#.
+			  #...#*
+			  #####.`
+
+	for _, opts := range []Options{{}, {Algorithm: AStar, Heuristic: Zero}, {Workers: 2}} {
+		result, err := Solve(context.Background(), newGame(t, state), opts)
+		if !errors.Is(err, ErrNoSolution) {
+			t.Fatalf("expected no solution, got %v", err)
+		}
+		if result.Peak < result.Visited {
+			t.Fatalf("%s with %d workers: expected the peak to count all %d states seen, got %d",
+				opts.Algorithm, opts.Workers, result.Visited, result.Peak)
+		}
+	}
+}
+
+func TestSolveInformed(t *testing.T) {
+	for _, algorithm := range []Algorithm{AStar, IDAStar} {
+		for _, h := range Heuristics {
+			for _, tc := range solveCases {
+				t.Run(algorithm.String()+"/"+h.Name+"/"+tc.name, func(t *testing.T) {
+					state := tc.state
+					result, err := Solve(context.Background(), newGame(t, state), Options{Algorithm: algorithm, Heuristic: h})
+					if tc.length == -1 {
+						if !errors.Is(err, ErrNoSolution) {
+							t.Fatalf("expected no solution, got %v %s", err, game.FormatMoves(result.Moves))
+						}
+						return
+					}
+					if err != nil {
+						t.Fatalf("unexpected error: %v", err)
+					}
+					if result.Optimal != h.Admissible {
+						t.Fatalf("expected optimal to be %v", h.Admissible)
+					}
+					if h.Admissible && len(result.Moves) != tc.length {
+						t.Fatalf("expected %d moves, got %s", tc.length, game.FormatMoves(result.Moves))
+					}
+					replay(t, state, result.Moves)
+				})
+			}
+		}
+	}
+}
+
+func TestSolveClassic(t *testing.T) {
+	level, err := levels.Get("classic", "01-the-problem")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result, err := Solve(context.Background(), newGame(t, level.Data), Options{Algorithm: AStar, Heuristic: Distance})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Moves) != 23 || !result.Optimal {
+		t.Fatalf("expected an optimal solution of 23 moves, got %s", game.FormatMoves(result.Moves))
+	}
+	replay(t, level.Data, result.Moves)
+}
+
+func TestParseAlgorithm(t *testing.T) {
+	for _, a := range []Algorithm{BFS, AStar, IDAStar} {
+		parsed, err := ParseAlgorithm(a.String())
+		if err != nil || parsed != a {
+			t.Fatalf("expected %s, got %s %v", a, parsed, err)
+		}
+	}
+	if _, err := ParseAlgorithm("dfs"); err == nil {
+		t.Fatalf("expected an error")
+	}
+}
@@ -0,0 +1,84 @@
+package solver
+
+import "sync"
+
+// tableShards splits the transposition table so workers rarely wait on the same lock
+const tableShards = 64
+
+// entry is the best way found to a state, the fewest moves and then the lowest order.
+type entry struct {
+	depth int
+	order uint64
+}
+
+func (e entry) before(other entry) bool {
+	if e.depth != other.depth {
+		return e.depth < other.depth
+	}
+	return e.order < other.order
+}
+
+type tableShard struct {
+	mu      sync.Mutex
+	entries map[string]entry
+}
+
+// table is a transposition table that is safe to use from several goroutines,
+// states are sharded by a hash of their key.
+type table struct {
+	shards [tableShards]tableShard
+}
+
+func newTable() *table {
+	t := &table{}
+	for i := range t.shards {
+		t.shards[i].entries = make(map[string]entry)
+	}
+	return t
+}
+
+// fnv-1a, inlined so hashing a key doesn't allocate
+func hashKey(key string) uint64 {
+	h := uint64(14695981039346656037)
+	for i := 0; i < len(key); i++ {
+		h ^= uint64(key[i])
+		h *= 1099511628211
+	}
+	return h
+}
+
+func (t *table) shard(key string) *tableShard {
+	return &t.shards[hashKey(key)%tableShards]
+}
+
+// claim records e for key if it comes before what was recorded so far.
+// The same entries are kept no matter what order they are claimed in.
+func (t *table) claim(key string, e entry) bool {
+	s := t.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if old, ok := s.entries[key]; ok && !e.before(old) {
+		return false
+	}
+	s.entries[key] = e
+	return true
+}
+
+func (t *table) get(key string) (entry, bool) {
+	s := t.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	return e, ok
+}
+
+func (t *table) len() int {
+	n := 0
+	for i := range t.shards {
+		s := &t.shards[i]
+		s.mu.Lock()
+		n += len(s.entries)
+		s.mu.Unlock()
+	}
+	return n
+}
@@ -0,0 +1,298 @@
+// Package stats measures how hard a level is by exploring every state it can reach.
+package stats
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slimesolver/game"
+	"slimesolver/solver"
+)
+
+// DefaultMaxStates bounds the exploration when Options.MaxStates isn't set.
+const DefaultMaxStates = 100000
+
+// how many states are expanded between checks of the context
+const cancelCheckInterval = 256
+
+type Options struct {
+	// MaxStates stops exploring after this many distinct states,
+	// the counts are then only of what was explored.
+	// A search for a solution expanding as many states takes over when none was found by then.
+	MaxStates int
+}
+
+// Interactions counts the moves of the shortest solution that depend on actors moving at the same time.
+type Interactions struct {
+	// Vacated moves have an actor move onto a tile another actor left the same move
+	Vacated int `json:"vacated"`
+	// Combines moves have two small slimes grow into one
+	Combines int `json:"combines"`
+	// Pushes moves have a slime push a box
+	Pushes int `json:"pushes"`
+	// Moves have at least one of the above
+	Moves int `json:"moves"`
+}
+
+// Stats are the metrics of a level used to order a pack.
+type Stats struct {
+	Level    string `json:"level,omitempty"`
+	Solvable bool   `json:"solvable"`
+	// Unknown is set when exploring and searching both stopped before finding out whether
+	// the level can be solved, Solvable is false then
+	Unknown bool `json:"unknown,omitempty"`
+	// Length of the shortest solution, 0 when it can't be solved
+	Length int `json:"length"`
+	// MinLength is the fewest moves a solution can have when it is Unknown,
+	// every state closer to the start was explored
+	MinLength int    `json:"min_length,omitempty"`
+	Solution  string `json:"solution"`
+	// Branching is the average number of different states one move leads to
+	Branching float64 `json:"branching"`
+	// States is the number of distinct states reachable from the start
+	States int `json:"states"`
+	// DeadEnds are reachable states from which the level can't be won,
+	// only states whose every reachable state was explored are counted
+	DeadEnds int `json:"dead_ends"`
+	// Complete is false when exploring stopped at Options.MaxStates
+	Complete     bool         `json:"complete"`
+	Interactions Interactions `json:"interactions"`
+	Score        float64      `json:"score"`
+}
+
+type explored struct {
+	parent   int
+	move     game.Direction
+	depth    int
+	children []int
+	won      bool
+	// some children weren't explored because of Options.MaxStates
+	cut bool
+}
+
+// Compute explores every state reachable from g with a breadth first search.
+// g is not modified.
+func Compute(ctx context.Context, g *game.Game, opts Options) (Stats, error) {
+	maxStates := opts.MaxStates
+	if maxStates <= 0 {
+		maxStates = DefaultMaxStates
+	}
+
+	start := g.Clone()
+	ids := map[string]int{start.Key(): 0}
+	states := []explored{{parent: -1, won: start.Won()}}
+	queue := []*game.Game{start}
+	s := Stats{Complete: true}
+
+	goal := -1
+	if states[0].won {
+		goal = 0
+	}
+
+	expanded, successors := 0, 0
+	for i := 0; i < len(queue); i++ {
+		if i%cancelCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return s, err
+			}
+		}
+
+		current := queue[i]
+		queue[i] = nil // only the frontier is kept in memory
+		if states[i].won || solver.Lost(current) {
+			continue
+		}
+		expanded++
+
+		key := current.Key()
+		for _, dir := range solver.Directions {
+			next := current.Clone()
+			next.Move(dir)
+			nextKey := next.Key()
+			if nextKey == key {
+				continue
+			}
+
+			id, ok := ids[nextKey]
+			if !ok {
+				if len(states) >= maxStates {
+					s.Complete = false
+					states[i].cut = true
+					continue
+				}
+				id = len(states)
+				ids[nextKey] = id
+				states = append(states, explored{parent: i, move: dir, depth: states[i].depth + 1, won: next.Won()})
+				queue = append(queue, next)
+				if goal == -1 && states[id].won {
+					goal = id
+				}
+			}
+			states[i].children = append(states[i].children, id)
+			successors++
+		}
+	}
+
+	s.States = len(states)
+	if expanded > 0 {
+		s.Branching = math.Round(float64(successors)/float64(expanded)*100) / 100
+	}
+	s.DeadEnds = deadEnds(states)
+
+	var moves []game.Direction
+	switch {
+	case goal != -1:
+		moves = path(states, goal)
+	case !s.Complete:
+		// the solution is further away than exploring got, searching towards it can still find it
+		opts := solver.Options{MaxNodes: maxStates, Algorithm: solver.AStar, Heuristic: solver.Distance, Prune: true}
+		result, err := solver.Solve(ctx, g, opts)
+		switch {
+		case err == nil:
+			moves = result.Moves
+		case errors.Is(err, solver.ErrLimit):
+			s.Unknown = true
+			// no explored state wins and the ones left out are at least as far from the start
+			s.MinLength = states[len(states)-1].depth
+		case !errors.Is(err, solver.ErrNoSolution):
+			return s, err
+		}
+	}
+	if moves != nil {
+		s.Solvable = true
+		s.Length = len(moves)
+		s.Solution = game.FormatMoves(moves)
+		s.Interactions = interactions(g, moves)
+	}
+	s.Score = score(s)
+	return s, nil
+}
+
+// path walks back from a state to the start, breadth first search makes it a shortest one.
+func path(states []explored, id int) []game.Direction {
+	var moves []game.Direction
+	for ; states[id].parent != -1; id = states[id].parent {
+		moves = append(moves, states[id].move)
+	}
+	for i, j := 0, len(moves)-1; i < j; i, j = i+1, j-1 {
+		moves[i], moves[j] = moves[j], moves[i]
+	}
+	return moves
+}
+
+// deadEnds counts the states no won state can be reached from, walking every move backwards from the won states.
+// States that can reach one whose children weren't all explored might still be won and aren't counted.
+func deadEnds(states []explored) int {
+	parents := make([][]int, len(states))
+	for id, state := range states {
+		for _, child := range state.children {
+			parents[child] = append(parents[child], id)
+		}
+	}
+	winnable := backwards(states, parents, func(s explored) bool { return s.won })
+	open := backwards(states, parents, func(s explored) bool { return s.cut })
+
+	n := 0
+	for id := range states {
+		if !winnable[id] && !open[id] {
+			n++
+		}
+	}
+	return n
+}
+
+// backwards marks the states from which a state matching from can be reached.
+func backwards(states []explored, parents [][]int, from func(explored) bool) []bool {
+	marked := make([]bool, len(states))
+	var queue []int
+	for id, state := range states {
+		if from(state) {
+			marked[id] = true
+			queue = append(queue, id)
+		}
+	}
+
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, parent := range parents[id] {
+			if !marked[parent] {
+				marked[parent] = true
+				queue = append(queue, parent)
+			}
+		}
+	}
+	return marked
+}
+
+// interactions replays moves and counts the ones that only work because actors move together.
+func interactions(g *game.Game, moves []game.Direction) Interactions {
+	var in Interactions
+	current := g.Clone()
+	for _, dir := range moves {
+		report := current.MoveReport(dir)
+
+		// boxes pushed into a pit are killed where they fell
+		moved := report.Moved
+		for _, killed := range report.Killed {
+			if !killed.From.Equals(killed.To) {
+				moved = append(moved, killed)
+			}
+		}
+
+		vacated, combined, pushed := false, false, false
+		for i, a := range moved {
+			if game.IsBoxToken(a.Token) {
+				pushed = true
+			}
+			// moving onto a box that moved away is a push
+			for j, b := range moved {
+				if i != j && !game.IsBoxToken(b.Token) && a.To.Equals(b.From) {
+					vacated = true
+				}
+			}
+		}
+		for _, c := range report.Changed {
+			if game.IsSlimeToken(c.Token) && game.SlimeSize(c.NewToken) > game.SlimeSize(c.Token) {
+				combined = true
+			}
+		}
+
+		if vacated {
+			in.Vacated++
+		}
+		if combined {
+			in.Combines++
+		}
+		if pushed {
+			in.Pushes++
+		}
+		if vacated || combined || pushed {
+			in.Moves++
+		}
+	}
+	return in
+}
+
+// score combines the metrics into one number, higher is harder:
+//
+//	length * (1 + share of dead ends) + 2 * interaction moves + log2(states)
+//
+// Longer solutions are harder, more so when most moves lead nowhere, and so are
+// moves that need actors to move together. The state space only adds a little since
+// large open levels aren't hard by themselves.
+// Levels that can't be solved score 0, Unknown ones score at least what their MinLength would.
+func score(s Stats) float64 {
+	length := s.Length
+	if s.Unknown {
+		length = s.MinLength
+	} else if !s.Solvable {
+		return 0
+	}
+	if s.States == 0 {
+		return 0
+	}
+	deadShare := float64(s.DeadEnds) / float64(s.States)
+	score := float64(length)*(1+deadShare) + 2*float64(s.Interactions.Moves) + math.Log2(float64(s.States))
+	return math.Round(score*100) / 100
+}
@@ -0,0 +1,118 @@
+package stats
+
+import (
+	"context"
+	"slimesolver/game"
+	"testing"
+)
+
+func newGame(t *testing.T, state string) *game.Game {
+	t.Helper()
+	g := game.NewGame(nil)
+	if err := g.Parse(state); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return g
+}
+
+func TestCompute(t *testing.T) {
+	tt := []struct {
+		name  string
+		state string
+		want  Stats
+	}{
+		{
+			name:  "walk",
+			state: `@..*`,
+			want:  Stats{Solvable: true, Length: 3, Solution: "rrr", States: 8, Complete: true},
+		},
+		{
+			name:  "push into pit",
+			state: `@BO.*`,
+			want: Stats{Solvable: true, Length: 4, Solution: "rrrr", States: 12, Complete: true,
+				Interactions: Interactions{Pushes: 1, Moves: 1}},
+		},
+		{
+			name:  "follow the leader",
+			state: `@@..*`,
+			want: Stats{Solvable: true, Length: 3, Solution: "rrr", States: 10, Complete: true,
+				Interactions: Interactions{Vacated: 3, Moves: 3}},
+		},
+		{
+			name:  "split on spike",
+			state: `@-..*`,
+			want: Stats{Solvable: true, Length: 4, Solution: "rrrr", States: 47, DeadEnds: 1, Complete: true,
+				Interactions: Interactions{Vacated: 3, Moves: 3}},
+		},
+		{
+			name:  "pit in the way",
+			state: `@.O*`,
+			want:  Stats{States: 5, DeadEnds: 5, Complete: true},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := Compute(context.Background(), newGame(t, tc.state), Options{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// checked on their own
+			tc.want.Branching, tc.want.Score = s.Branching, s.Score
+			if s != tc.want {
+				t.Fatalf("expected %+v, got %+v", tc.want, s)
+			}
+			if s.Solvable && (s.Score <= 0 || s.Branching <= 0) {
+				t.Fatalf("expected a score and branching factor, got %+v", s)
+			}
+			if !s.Solvable && s.Score != 0 {
+				t.Fatalf("expected unsolvable levels to score 0, got %v", s.Score)
+			}
+		})
+	}
+}
+
+func TestComputeLimit(t *testing.T) {
+	state := `@...*
+			  .....`
+	s, err := Compute(context.Background(), newGame(t, state), Options{MaxStates: 5})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Complete || s.States != 5 {
+		t.Fatalf("expected to stop at 5 states, got %+v", s)
+	}
+	// the search that takes over finds the solution exploring didn't get to
+	if !s.Solvable || s.Unknown || s.Solution != "rrrr" || s.DeadEnds != 0 || s.Score <= 0 {
+		t.Fatalf("expected the level to be solved, got %+v", s)
+	}
+
+	// neither gets around the wall
+	state = `@#....
+			 .#.##.
+			 ...#*.`
+	s, err = Compute(context.Background(), newGame(t, state), Options{MaxStates: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Solvable || !s.Unknown || s.MinLength != 2 || s.DeadEnds != 0 || s.Score <= 0 {
+		t.Fatalf("expected the level to be unknown, got %+v", s)
+	}
+}
+
+func TestCombines(t *testing.T) {
+	in := interactions(newGame(t, `#oo*`), []game.Direction{game.Left})
+	if in.Combines != 1 || in.Moves != 1 {
+		t.Fatalf("expected the small slimes to combine, got %+v", in)
+	}
+}
+
+func TestScore(t *testing.T) {
+	easy := score(Stats{Solvable: true, Length: 4, States: 12})
+	dead := score(Stats{Solvable: true, Length: 4, States: 12, DeadEnds: 6})
+	together := score(Stats{Solvable: true, Length: 4, States: 12, Interactions: Interactions{Moves: 1}})
+	if easy >= dead || easy >= together {
+		t.Fatalf("expected dead ends and interactions to make a level harder, got %v %v %v", easy, dead, together)
+	}
+}